@@ -15,6 +15,30 @@ func BenchmarkSet(b *testing.B) {
 	}
 }
 
+// BenchmarkSetForwardOnly measures writes that skip reverse-index maintenance
+func BenchmarkSetForwardOnly(b *testing.B) {
+	m := NewForwardOnly[int, string]()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		m.Set(i, fmt.Sprintf("value-%d", i%100))
+	}
+}
+
+// BenchmarkSetMany measures the performance of batched Set operations
+func BenchmarkSetMany(b *testing.B) {
+	batch := make(map[int]string, 1000)
+	for i := 0; i < 1000; i++ {
+		batch[i] = fmt.Sprintf("value-%d", i%100)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m := NewWithCapacity[int, string](1000)
+		m.SetMany(batch)
+	}
+}
+
 // BenchmarkGet measures the performance of Get operations
 func BenchmarkGet(b *testing.B) {
 	m := NewWithCapacity[int, string](1000)
@@ -45,6 +69,51 @@ func BenchmarkGetKeys(b *testing.B) {
 	}
 }
 
+// BenchmarkGetKeysInto measures reverse lookups into a reused buffer
+func BenchmarkGetKeysInto(b *testing.B) {
+	m := NewWithCapacity[int, string](1000)
+
+	// Setup data with duplicate values
+	for i := 0; i < 1000; i++ {
+		m.Set(i, fmt.Sprintf("value-%d", i%10)) // 10 different values, 100 keys each
+	}
+
+	var buf []int
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf = m.GetKeysInto(fmt.Sprintf("value-%d", i%10), buf)
+	}
+}
+
+// BenchmarkListPooled measures key snapshots drawn from the map's buffer pool
+func BenchmarkListPooled(b *testing.B) {
+	m := NewWithCapacity[int, int](1000)
+	for i := 0; i < 1000; i++ {
+		m.Set(i, i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, release := m.ListPooled()
+		release()
+	}
+}
+
+// BenchmarkCountKeys measures the performance of reverse-index cardinality lookups
+func BenchmarkCountKeys(b *testing.B) {
+	m := NewWithCapacity[int, string](1000)
+
+	// Setup data with duplicate values
+	for i := 0; i < 1000; i++ {
+		m.Set(i, fmt.Sprintf("value-%d", i%10)) // 10 different values, 100 keys each
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = m.CountKeys(fmt.Sprintf("value-%d", i%10))
+	}
+}
+
 // BenchmarkRemove measures the performance of Remove operations
 func BenchmarkRemove(b *testing.B) {
 	// Setup a fresh map for each benchmark run
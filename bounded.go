@@ -0,0 +1,56 @@
+package genericmap
+
+// NewBounded creates a new generic map that holds at most maxSize entries
+// and refuses to insert new keys beyond that, instead of evicting like
+// NewLRU. Updating an existing key is always allowed since it does not grow
+// the map. It panics if maxSize is not positive.
+//
+// Use Offer to insert and learn whether the entry was accepted, so callers
+// can shed load. Other write methods, such as Set or SetMany, silently skip
+// new keys while the map is full; SetIfAbsent reports them as not inserted.
+func NewBounded[K comparable, V comparable](maxSize int) *Map[K, V] {
+	if maxSize <= 0 {
+		panic("genericmap: NewBounded requires a positive maxSize")
+	}
+	m := newMap[K, V](maxSize)
+	m.bound = maxSize
+	return m
+}
+
+// Offer adds or updates a key-value pair like Set, and reports whether it
+// was stored. It only returns false on a map created with NewBounded that
+// is full and does not already hold the key, or when a map created with
+// NewWithMaxKeysPerValue and FanoutReject refuses the write.
+func (m *Map[K, V]) Offer(key K, value V) bool {
+	if traceEnabled {
+		defer traceStart("Offer")()
+	}
+	key = m.normKey(key)
+	m.lock()
+	defer m.unlock()
+
+	m.expireLocked(key)
+	return m.setLocked(key, value)
+}
+
+// GetOrOffer is GetOrSet for maps that can refuse writes. It returns the
+// existing value for the key with loaded set to true if present. Otherwise
+// it offers value as Offer does: stored reports whether it was inserted,
+// and actual is value if so and the zero value if the map refused it.
+func (m *Map[K, V]) GetOrOffer(key K, value V) (actual V, loaded, stored bool) {
+	if traceEnabled {
+		defer traceStart("GetOrOffer")()
+	}
+	key = m.normKey(key)
+	m.lock()
+	defer m.unlock()
+
+	m.expireLocked(key)
+	if existing, exists := m.data[key]; exists {
+		return existing, true, false
+	}
+	if !m.setLocked(key, value) {
+		return actual, false, false
+	}
+	return value, false, true
+}
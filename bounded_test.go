@@ -0,0 +1,108 @@
+package genericmap
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestNewBounded(t *testing.T) {
+	m := NewBounded[string, int](2)
+
+	if !m.Offer("a", 1) || !m.Offer("b", 1) {
+		t.Fatalf("Expected inserts below the bound to be accepted")
+	}
+	if m.Offer("c", 2) {
+		t.Errorf("Expected insert past the bound to be refused")
+	}
+	if m.Contains("c") || m.ContainsValue(2) {
+		t.Errorf("Expected refused entry to be absent from both indexes")
+	}
+
+	// Updating an existing key never grows the map
+	if !m.Offer("a", 3) {
+		t.Errorf("Expected update of an existing key to be accepted")
+	}
+	if val, _ := m.Get("a"); val != 3 {
+		t.Errorf("Expected a=3, got %d", val)
+	}
+
+	// Other writers skip new keys while full
+	m.Set("d", 4)
+	if m.SetIfAbsent("e", 5) {
+		t.Errorf("Expected SetIfAbsent to report a refused insert")
+	}
+	if m.Len() != 2 {
+		t.Errorf("Expected length to stay at 2, got %d", m.Len())
+	}
+
+	// Room frees up after a removal
+	m.Remove("b")
+	if !m.Offer("c", 2) {
+		t.Errorf("Expected insert to succeed after a removal")
+	}
+	if err := m.CheckIntegrity(); err != nil {
+		t.Errorf("Expected consistent map, got %v", err)
+	}
+}
+
+func TestBoundedRefusals(t *testing.T) {
+	m := NewBounded[string, int](1)
+	m.Set("a", 1)
+
+	if actual, loaded := m.GetOrSet("x", 9); actual != 0 || loaded {
+		t.Errorf("Expected GetOrSet to return (0, false) for a refused insert, got (%v, %v)", actual, loaded)
+	}
+	if actual, loaded, stored := m.GetOrOffer("x", 9); actual != 0 || loaded || stored {
+		t.Errorf("Expected GetOrOffer to report a refused insert, got (%v, %v, %v)", actual, loaded, stored)
+	}
+	if actual, loaded, stored := m.GetOrOffer("a", 9); actual != 1 || !loaded || stored {
+		t.Errorf("Expected GetOrOffer to load a=1, got (%v, %v, %v)", actual, loaded, stored)
+	}
+	if err := m.SetContext(context.Background(), "x", 9); !errors.Is(err, ErrRefused) {
+		t.Errorf("Expected ErrRefused from SetContext, got %v", err)
+	}
+	if err := m.SetContext(context.Background(), "a", 2); err != nil {
+		t.Errorf("Expected SetContext to update an existing key, got %v", err)
+	}
+	if m.TrySet("x", 9) {
+		t.Errorf("Expected TrySet to report a refused insert")
+	}
+	if _, moved := m.Move("x", 9); moved {
+		t.Errorf("Expected Move to report a refused insert")
+	}
+	if inserted, _ := m.SetValidated(map[string]int{"x": 9}, func(string, int) error { return nil }); inserted != 0 {
+		t.Errorf("Expected SetValidated to count no refused inserts, got %d", inserted)
+	}
+	m.Swap("x", 9)
+	if m.Contains("x") || m.ContainsValue(9) || m.Len() != 1 {
+		t.Errorf("Expected refused writes to leave the map unchanged, got %v", m)
+	}
+
+	counters := NewBounded[string, int64](1)
+	Increment(counters, "a", 5)
+	if n := Increment(counters, "b", 3); n != 0 || counters.Contains("b") {
+		t.Errorf("Expected refused Increment to return 0 and store nothing, got %d", n)
+	}
+	if n := Increment(counters, "a", 1); n != 6 {
+		t.Errorf("Expected a=6, got %d", n)
+	}
+}
+
+func TestOfferUnbounded(t *testing.T) {
+	m := New[int, int]()
+	for i := 0; i < 100; i++ {
+		if !m.Offer(i, i) {
+			t.Fatalf("Expected Offer on an unbounded map to always succeed")
+		}
+	}
+}
+
+func TestNewBoundedInvalidSize(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected NewBounded(0) to panic")
+		}
+	}()
+	NewBounded[string, int](0)
+}
@@ -0,0 +1,52 @@
+package genericmap
+
+import "context"
+
+// GetKeysChan streams the keys associated with value over a channel, for
+// processing large reverse lookups incrementally. The key set is copied
+// under the read lock when GetKeysChan is called and then fed to the
+// channel by a background goroutine, so a slow consumer never holds the
+// lock; keys added or removed afterwards are not reflected. The channel is
+// closed once every key has been sent, so it can be ranged over.
+//
+// A consumer that stops early must cancel ctx. The feeding goroutine then
+// exits and closes the channel, releasing the snapshot, whether or not
+// every key was sent.
+func (m *Map[K, V]) GetKeysChan(ctx context.Context, value V) <-chan K {
+	if traceEnabled {
+		defer traceStart("GetKeysChan")()
+	}
+	return feed(ctx, m.GetKeys(value))
+}
+
+// KeysChan streams every key in the map over a channel, for fan-out to
+// worker pools and other pipeline stages. Like GetKeysChan it reflects a
+// snapshot of the keys taken under the read lock at call time, not live
+// updates, and closes the channel once every key has been sent or ctx is
+// cancelled. The snapshot holds the keys only; values are never copied.
+//
+// Workers that may stop before the channel is drained must cancel ctx, or
+// the feeding goroutine and the snapshot it holds are never released.
+func (m *Map[K, V]) KeysChan(ctx context.Context) <-chan K {
+	if traceEnabled {
+		defer traceStart("KeysChan")()
+	}
+	return feed(ctx, m.List())
+}
+
+// feed sends items over a new channel from a background goroutine and
+// closes the channel afterwards, or as soon as ctx is done.
+func feed[T any](ctx context.Context, items []T) <-chan T {
+	ch := make(chan T)
+	go func() {
+		defer close(ch)
+		for _, item := range items {
+			select {
+			case ch <- item:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
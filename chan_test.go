@@ -0,0 +1,89 @@
+package genericmap
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestGetKeysChan(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 1, "c": 2})
+
+	ch := m.GetKeysChan(context.Background(), 1)
+
+	// Changes after the call are not reflected in the stream
+	m.Set("d", 1)
+	m.Remove("a")
+
+	var keys []string
+	for k := range ch {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+		t.Errorf("Expected snapshot keys [a b], got %v", keys)
+	}
+
+	// An absent value yields a closed, empty channel
+	for k := range m.GetKeysChan(context.Background(), 9) {
+		t.Errorf("Expected no keys for absent value, got %v", k)
+	}
+}
+
+func TestKeysChan(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 2, "c": 3})
+
+	ch := m.KeysChan(context.Background())
+	m.Set("d", 4)
+	m.Clear()
+
+	var keys []string
+	for k := range ch {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if len(keys) != 3 || keys[0] != "a" || keys[1] != "b" || keys[2] != "c" {
+		t.Errorf("Expected snapshot keys [a b c], got %v", keys)
+	}
+
+	for k := range m.KeysChan(context.Background()) {
+		t.Errorf("Expected no keys for empty map, got %v", k)
+	}
+}
+
+func TestKeysChanCancel(t *testing.T) {
+	m := New[int, int]()
+	for i := 0; i < 100; i++ {
+		m.Set(i, i%2)
+	}
+
+	for name, stream := range map[string]func(context.Context) <-chan int{
+		"KeysChan":    m.KeysChan,
+		"GetKeysChan": func(ctx context.Context) <-chan int { return m.GetKeysChan(ctx, 0) },
+	} {
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := stream(ctx)
+		<-ch
+		cancel()
+
+		// The feeder stops and closes the channel without being drained
+		deadline := time.After(time.Second)
+		received := 1
+	drain:
+		for {
+			select {
+			case _, ok := <-ch:
+				if !ok {
+					break drain
+				}
+				received++
+			case <-deadline:
+				t.Fatalf("Expected %s channel to close after cancellation", name)
+			}
+		}
+		if received >= 50 {
+			t.Errorf("Expected %s to stop early, got %d keys", name, received)
+		}
+	}
+}
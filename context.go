@@ -0,0 +1,81 @@
+package genericmap
+
+import (
+	"context"
+	"time"
+)
+
+// Bounds for the wait between attempts to acquire a contended lock
+// in SetContext.
+const (
+	minLockBackoff = time.Microsecond
+	maxLockBackoff = time.Millisecond
+)
+
+// SetContext adds or updates a key-value pair like Set, but gives up if ctx
+// is cancelled before the write lock can be acquired, returning ctx.Err().
+// Once the lock is held the write completes, unless the map refuses it as
+// described for Offer, in which case ErrRefused is returned.
+//
+// sync.RWMutex cannot be waited on with a context, so SetContext polls
+// TryLock with an exponential backoff between attempts. Under heavy
+// contention it may therefore acquire the lock slightly later than Set would.
+func (m *Map[K, V]) SetContext(ctx context.Context, key K, value V) error {
+	if traceEnabled {
+		defer traceStart("SetContext")()
+	}
+	key = m.normKey(key)
+	if err := m.lockContext(ctx); err != nil {
+		return err
+	}
+	defer m.unlock()
+
+	if !m.setLocked(key, value) {
+		return ErrRefused
+	}
+	return nil
+}
+
+// lockContext acquires the write lock, or returns ctx.Err() if ctx is done
+// first. It returns ErrFrozen, without holding the lock, if the map is
+// frozen, including when Freeze ran while it was waiting.
+func (m *Map[K, V]) lockContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if m.frozen.Load() {
+		return ErrFrozen
+	}
+	if m.mu.TryLock() {
+		return m.checkFrozenContext()
+	}
+
+	backoff := minLockBackoff
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+		if m.mu.TryLock() {
+			return m.checkFrozenContext()
+		}
+		if backoff < maxLockBackoff {
+			backoff *= 2
+		}
+		timer.Reset(backoff)
+	}
+}
+
+// checkFrozenContext is the error-returning counterpart of
+// checkFrozenLocked: it releases the write lock and returns ErrFrozen if the
+// map is frozen.
+func (m *Map[K, V]) checkFrozenContext() error {
+	if m.frozen.Load() {
+		m.mu.Unlock()
+		return ErrFrozen
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package genericmap
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSetContext(t *testing.T) {
+	m := New[string, int]()
+
+	if err := m.SetContext(context.Background(), "a", 1); err != nil {
+		t.Fatalf("SetContext failed: %v", err)
+	}
+	if val, ok := m.Get("a"); !ok || val != 1 {
+		t.Errorf("Expected a=1, got %v, exists: %v", val, ok)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := m.SetContext(ctx, "b", 2); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled for cancelled context, got %v", err)
+	}
+	if m.Contains("b") {
+		t.Errorf("Expected cancelled SetContext not to write")
+	}
+}
+
+func TestSetContextContention(t *testing.T) {
+	m := New[string, int]()
+
+	// Hold the write lock so SetContext has to wait
+	m.mu.Lock()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := m.SetContext(ctx, "a", 1)
+	m.mu.Unlock()
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded under contention, got %v", err)
+	}
+	if m.Contains("a") {
+		t.Errorf("Expected abandoned SetContext not to write")
+	}
+
+	// Once the lock is released in time the write goes through
+	m.mu.Lock()
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		m.mu.Unlock()
+	}()
+	if err := m.SetContext(context.Background(), "a", 1); err != nil {
+		t.Errorf("Expected SetContext to succeed after the lock is released, got %v", err)
+	}
+	if !m.Contains("a") {
+		t.Errorf("Expected a to be set")
+	}
+}
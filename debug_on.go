@@ -0,0 +1,7 @@
+//go:build genericmap_debug
+
+package genericmap
+
+// debugChecks enables internal consistency assertions. It is set by
+// building with the genericmap_debug tag.
+const debugChecks = true
@@ -1,7 +1,7 @@
 // Package genericmap provides a thread-safe, generic bidirectional map implementation.
 //
 // The genericmap package offers a highly efficient map with both forward (key->value)
-// and reverse (value->keys) lookup capabilities, designed specifically for Go 1.18+.
+// and reverse (value->keys) lookup capabilities, designed specifically for Go 1.24+.
 //
 // Features:
 //
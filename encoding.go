@@ -0,0 +1,241 @@
+package genericmap
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// GobEncode implements gob.GobEncoder.
+// Only the forward data is encoded; the reverse index is derived state and is
+// rebuilt on decode, which keeps the wire format compact.
+func (m *Map[K, V]) GobEncode() ([]byte, error) {
+	if traceEnabled {
+		defer traceStart("GobEncode")()
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(m.data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode implements gob.GobDecoder.
+// It replaces the map's contents with the decoded data and rebuilds the
+// reverse index. It can be used on a zero Map value.
+func (m *Map[K, V]) GobDecode(b []byte) error {
+	if traceEnabled {
+		defer traceStart("GobDecode")()
+	}
+	var data map[K]V
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&data); err != nil {
+		return err
+	}
+
+	m.lock()
+	defer m.unlock()
+
+	if m.data == nil {
+		m.initLocked(len(data))
+	} else {
+		m.clearLocked()
+	}
+	for k, v := range data {
+		m.setLocked(m.normKey(k), v)
+	}
+	return nil
+}
+
+// binaryVersion identifies the layout produced by MarshalBinary.
+const binaryVersion = 1
+
+// MarshalBinary implements encoding.BinaryMarshaler using a compact,
+// length-prefixed layout: a version byte, the number of entries, then each
+// key followed by its value. Only the forward data is encoded.
+//
+// Keys and values must be booleans, integers, floats or strings (including
+// named types of those kinds), or implement encoding.BinaryMarshaler.
+// Other types make MarshalBinary return an error.
+func (m *Map[K, V]) MarshalBinary() ([]byte, error) {
+	if traceEnabled {
+		defer traceStart("MarshalBinary")()
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	b := make([]byte, 0, 1+binary.MaxVarintLen64+len(m.data)*16)
+	b = append(b, binaryVersion)
+	b = binary.AppendUvarint(b, uint64(len(m.data)))
+	var err error
+	for k, v := range m.data {
+		if b, err = appendBinary(b, k); err != nil {
+			return nil, err
+		}
+		if b, err = appendBinary(b, v); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+// It replaces the map's contents with the decoded data and rebuilds the
+// reverse index. It can be used on a zero Map value. For types implementing
+// encoding.BinaryUnmarshaler, the method must be defined on the pointer
+// receiver.
+func (m *Map[K, V]) UnmarshalBinary(b []byte) error {
+	if traceEnabled {
+		defer traceStart("UnmarshalBinary")()
+	}
+	if len(b) == 0 || b[0] != binaryVersion {
+		return fmt.Errorf("%w: unknown version", errInvalidBinary)
+	}
+	b = b[1:]
+	n, size := binary.Uvarint(b)
+	if size <= 0 || n > uint64(len(b)) {
+		return fmt.Errorf("%w: bad entry count", errInvalidBinary)
+	}
+	b = b[size:]
+
+	data := make(map[K]V, n)
+	var err error
+	for i := uint64(0); i < n; i++ {
+		var k K
+		var v V
+		if b, err = readBinary(b, &k); err != nil {
+			return err
+		}
+		if b, err = readBinary(b, &v); err != nil {
+			return err
+		}
+		data[k] = v
+	}
+	if len(b) != 0 {
+		return fmt.Errorf("%w: trailing data", errInvalidBinary)
+	}
+
+	m.lock()
+	defer m.unlock()
+
+	if m.data == nil {
+		m.initLocked(len(data))
+	} else {
+		m.clearLocked()
+	}
+	for k, v := range data {
+		m.setLocked(m.normKey(k), v)
+	}
+	return nil
+}
+
+// errInvalidBinary is returned by UnmarshalBinary for malformed input.
+var errInvalidBinary = errors.New("genericmap: invalid binary encoding")
+
+// appendBinary appends the binary encoding of v to b.
+func appendBinary[T any](b []byte, v T) ([]byte, error) {
+	if bm, ok := any(v).(encoding.BinaryMarshaler); ok {
+		data, err := bm.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		b = binary.AppendUvarint(b, uint64(len(data)))
+		return append(b, data...), nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		if rv.Bool() {
+			return append(b, 1), nil
+		}
+		return append(b, 0), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return binary.AppendVarint(b, rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return binary.AppendUvarint(b, rv.Uint()), nil
+	case reflect.Float32:
+		return binary.LittleEndian.AppendUint32(b, math.Float32bits(float32(rv.Float()))), nil
+	case reflect.Float64:
+		return binary.LittleEndian.AppendUint64(b, math.Float64bits(rv.Float())), nil
+	case reflect.String:
+		b = binary.AppendUvarint(b, uint64(rv.Len()))
+		return append(b, rv.String()...), nil
+	default:
+		return nil, fmt.Errorf("genericmap: cannot binary-encode type %T", v)
+	}
+}
+
+// readBinary decodes a value written by appendBinary from the front of b
+// into v and returns the remaining bytes.
+func readBinary[T any](b []byte, v *T) ([]byte, error) {
+	if bu, ok := any(v).(encoding.BinaryUnmarshaler); ok {
+		data, rest, err := readBytes(b)
+		if err != nil {
+			return nil, err
+		}
+		return rest, bu.UnmarshalBinary(data)
+	}
+
+	rv := reflect.ValueOf(v).Elem()
+	switch rv.Kind() {
+	case reflect.Bool:
+		if len(b) < 1 {
+			return nil, fmt.Errorf("%w: truncated bool", errInvalidBinary)
+		}
+		rv.SetBool(b[0] != 0)
+		return b[1:], nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, size := binary.Varint(b)
+		if size <= 0 || rv.OverflowInt(x) {
+			return nil, fmt.Errorf("%w: bad integer", errInvalidBinary)
+		}
+		rv.SetInt(x)
+		return b[size:], nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		x, size := binary.Uvarint(b)
+		if size <= 0 || rv.OverflowUint(x) {
+			return nil, fmt.Errorf("%w: bad unsigned integer", errInvalidBinary)
+		}
+		rv.SetUint(x)
+		return b[size:], nil
+	case reflect.Float32:
+		if len(b) < 4 {
+			return nil, fmt.Errorf("%w: truncated float", errInvalidBinary)
+		}
+		rv.SetFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(b))))
+		return b[4:], nil
+	case reflect.Float64:
+		if len(b) < 8 {
+			return nil, fmt.Errorf("%w: truncated float", errInvalidBinary)
+		}
+		rv.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(b)))
+		return b[8:], nil
+	case reflect.String:
+		data, rest, err := readBytes(b)
+		if err != nil {
+			return nil, err
+		}
+		rv.SetString(string(data))
+		return rest, nil
+	default:
+		return nil, fmt.Errorf("genericmap: cannot binary-decode type %T", *v)
+	}
+}
+
+// readBytes reads a length-prefixed byte slice from the front of b.
+func readBytes(b []byte) (data, rest []byte, err error) {
+	n, size := binary.Uvarint(b)
+	if size <= 0 || n > uint64(len(b)-size) {
+		return nil, nil, fmt.Errorf("%w: truncated data", errInvalidBinary)
+	}
+	b = b[size:]
+	return b[:n], b[n:], nil
+}
@@ -0,0 +1,163 @@
+package genericmap
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"sort"
+	"testing"
+)
+
+func TestGobRoundTrip(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 2, "c": 1})
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(m); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	decoded := New[string, int]()
+	if err := gob.NewDecoder(&buf).Decode(decoded); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	if !decoded.Equal(m) {
+		t.Errorf("Expected decoded map to equal original, got %v", decoded)
+	}
+	keys := decoded.GetKeys(1)
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "c" {
+		t.Errorf("Expected rebuilt reverse index keys [a c] for value 1, got %v", keys)
+	}
+}
+
+func TestGobDecodeReplacesContents(t *testing.T) {
+	src := New[string, int](map[string]int{"a": 1})
+	data, err := src.GobEncode()
+	if err != nil {
+		t.Fatalf("GobEncode failed: %v", err)
+	}
+
+	dst := New[string, int](map[string]int{"x": 9})
+	if err := dst.GobDecode(data); err != nil {
+		t.Fatalf("GobDecode failed: %v", err)
+	}
+	if dst.Len() != 1 || dst.Contains("x") || dst.ContainsValue(9) {
+		t.Errorf("Expected previous contents to be replaced, got %v", dst)
+	}
+
+	// Decoding into a zero value works too
+	var zero Map[string, int]
+	if err := zero.GobDecode(data); err != nil {
+		t.Fatalf("GobDecode into zero value failed: %v", err)
+	}
+	if val, ok := zero.Get("a"); !ok || val != 1 {
+		t.Errorf("Expected a=1 in zero-value decode, got %v, exists: %v", val, ok)
+	}
+
+	if err := dst.GobDecode([]byte("garbage")); err == nil {
+		t.Errorf("Expected error decoding garbage input")
+	}
+}
+
+type userID int
+
+// point implements encoding.BinaryMarshaler on its value receiver and
+// encoding.BinaryUnmarshaler on its pointer receiver.
+type point struct{ X, Y int8 }
+
+func (p point) MarshalBinary() ([]byte, error) {
+	return []byte{byte(p.X), byte(p.Y)}, nil
+}
+
+func (p *point) UnmarshalBinary(b []byte) error {
+	if len(b) != 2 {
+		return errors.New("point: bad length")
+	}
+	p.X, p.Y = int8(b[0]), int8(b[1])
+	return nil
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	m := New[string, userID](map[string]userID{"alice": 1, "bob": -2, "carol": 1})
+
+	data, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+
+	var decoded Map[string, userID]
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+	if !decoded.Equal(m) {
+		t.Errorf("Expected decoded map to equal original, got %v", &decoded)
+	}
+	if n := decoded.CountKeys(1); n != 2 {
+		t.Errorf("Expected rebuilt reverse index with 2 keys for value 1, got %d", n)
+	}
+}
+
+func TestBinaryScalarKinds(t *testing.T) {
+	floats := New[float64, bool](map[float64]bool{1.5: true, -2.25: false})
+	data, err := floats.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+	decoded := New[float64, bool]()
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+	if !decoded.Equal(floats) {
+		t.Errorf("Expected %v, got %v", floats, decoded)
+	}
+
+	uints := New[uint16, float32](map[uint16]float32{65535: 0.5})
+	if data, err = uints.MarshalBinary(); err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+	decodedUints := New[uint16, float32]()
+	if err := decodedUints.UnmarshalBinary(data); err != nil || !decodedUints.Equal(uints) {
+		t.Errorf("Expected %v, got %v (err: %v)", uints, decodedUints, err)
+	}
+}
+
+func TestBinaryMarshalerValues(t *testing.T) {
+	m := New[string, point](map[string]point{"origin": {0, 0}, "corner": {-1, 7}})
+
+	data, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+	decoded := New[string, point]()
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+	if !decoded.Equal(m) {
+		t.Errorf("Expected %v, got %v", m, decoded)
+	}
+}
+
+func TestBinaryErrors(t *testing.T) {
+	type unsupported struct{ A int }
+	m := New[unsupported, int](map[unsupported]int{{1}: 1})
+	if _, err := m.MarshalBinary(); err == nil {
+		t.Errorf("Expected error for unsupported key type")
+	}
+
+	dst := New[string, int](map[string]int{"keep": 1})
+	for _, input := range [][]byte{nil, {0}, {binaryVersion}, {binaryVersion, 1}, {binaryVersion, 1, 5, 'a'}} {
+		if err := dst.UnmarshalBinary(input); err == nil {
+			t.Errorf("Expected error for malformed input %v", input)
+		}
+	}
+	if val, ok := dst.Get("keep"); !ok || val != 1 {
+		t.Errorf("Expected failed decode to leave the map unchanged, got %v", dst)
+	}
+
+	// Values that overflow the target type are rejected
+	wide, _ := New[string, int](map[string]int{"a": 300}).MarshalBinary()
+	if err := New[string, int8]().UnmarshalBinary(wide); err == nil {
+		t.Errorf("Expected overflow error decoding 300 into int8")
+	}
+}
@@ -0,0 +1,29 @@
+package genericmap
+
+import "errors"
+
+var (
+	// ErrDuplicateValue is returned when an operation requires every value in
+	// the map to be unique but two or more keys share the same value.
+	ErrDuplicateValue = errors.New("genericmap: duplicate value")
+
+	// ErrInconsistentIndex is returned by CheckIntegrity when the map's
+	// derived state has drifted from its forward data.
+	ErrInconsistentIndex = errors.New("genericmap: inconsistent index")
+
+	// ErrLengthMismatch is returned when parallel key and value slices
+	// passed to SetPairs differ in length.
+	ErrLengthMismatch = errors.New("genericmap: length mismatch")
+
+	// ErrKeyConflict is returned by NewStrict when the same key is given
+	// different values by two of the supplied maps.
+	ErrKeyConflict = errors.New("genericmap: conflicting values for key")
+
+	// ErrFrozen is returned by SetContext when the map has been frozen.
+	// Other mutating methods panic instead.
+	ErrFrozen = errors.New("genericmap: map is frozen")
+
+	// ErrRefused is returned by SetContext when the map refuses the write,
+	// as described for Offer.
+	ErrRefused = errors.New("genericmap: write refused")
+)
@@ -153,3 +153,18 @@ func ExampleMap_userGroupMapping() {
 	// Total groups: 5
 	// Unique groups: 3
 }
+
+// ExampleMap_SortedKeys demonstrates retrieving keys in a deterministic order.
+func ExampleMap_SortedKeys() {
+	m := New[string, int](map[string]int{"cherry": 5, "apple": 5, "banana": 2})
+
+	keys := m.SortedKeys(func(a, b string) bool { return a < b })
+	fmt.Printf("Sorted keys: %v\n", keys)
+
+	values := m.SortedValues(func(a, b int) bool { return a < b })
+	fmt.Printf("Sorted values: %v\n", values)
+
+	// Output:
+	// Sorted keys: [apple banana cherry]
+	// Sorted values: [2 5 5]
+}
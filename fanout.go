@@ -0,0 +1,127 @@
+package genericmap
+
+import "container/list"
+
+// FanoutPolicy selects what a map created with NewWithMaxKeysPerValue does
+// when a write would give a value more keys than its limit allows.
+type FanoutPolicy int
+
+const (
+	// FanoutReject refuses the write and leaves the map unchanged.
+	FanoutReject FanoutPolicy = iota
+
+	// FanoutEvictOldest removes the key that has held the value the longest
+	// to make room for the new one.
+	FanoutEvictOldest
+)
+
+// NewWithMaxKeysPerValue creates a new generic map in which no value is
+// held by more than limit keys, guarding the reverse index against a single
+// hot value accumulating an unbounded key set. It panics if limit is not
+// positive.
+//
+// A write that would map one key too many to a value is handled according
+// to policy. With FanoutReject the write is skipped, exactly like an insert
+// into a full NewBounded map: methods that report whether they stored,
+// such as Offer, SetReport or CompareAndSwap, report it as not stored,
+// others such as Set skip it silently, and SwapValue retags nothing if the
+// merged key set would be too large. With FanoutEvictOldest the keys that
+// have held the value the longest are removed entirely to make room, and
+// are reported to OnEvict and OnChange callbacks like LRU evictions.
+func NewWithMaxKeysPerValue[K comparable, V comparable](limit int, policy FanoutPolicy) *Map[K, V] {
+	if limit <= 0 {
+		panic("genericmap: NewWithMaxKeysPerValue requires a positive limit")
+	}
+	m := newMap[K, V](0)
+	m.fanoutLimit = limit
+	m.fanoutPolicy = policy
+	m.fanoutOrder = make(map[V]*list.List)
+	m.fanoutElems = make(map[K]*list.Element)
+	return m
+}
+
+// fanoutRoomLocked reports whether value can take one more key, evicting
+// its oldest key first under FanoutEvictOldest.
+// This is an internal method and assumes the caller holds the write lock.
+func (m *Map[K, V]) fanoutRoomLocked(value V) bool {
+	if len(m.reverseMap[value]) < m.fanoutLimit {
+		return true
+	}
+	if m.fanoutPolicy != FanoutEvictOldest {
+		return false
+	}
+	m.evictKeyLocked(m.fanoutOrder[value].Front().Value.(K))
+	return true
+}
+
+// trackFanoutLocked records key as the newest key of value.
+// This is an internal method and assumes the caller holds the write lock.
+func (m *Map[K, V]) trackFanoutLocked(key K, value V) {
+	keys := m.fanoutOrder[value]
+	if keys == nil {
+		keys = list.New()
+		m.fanoutOrder[value] = keys
+	}
+	m.fanoutElems[key] = keys.PushBack(key)
+}
+
+// untrackFanoutLocked forgets key as one of the keys of value.
+// This is an internal method and assumes the caller holds the write lock.
+func (m *Map[K, V]) untrackFanoutLocked(key K, value V) {
+	keys := m.fanoutOrder[value]
+	if keys == nil {
+		return
+	}
+	keys.Remove(m.fanoutElems[key])
+	delete(m.fanoutElems, key)
+	if keys.Len() == 0 {
+		delete(m.fanoutOrder, value)
+	}
+}
+
+// moveFanoutLocked appends the keys of from to those of to, as the newest,
+// after SwapValue has retagged them, then trims to back to the limit.
+// This is an internal method and assumes the caller holds the write lock.
+func (m *Map[K, V]) moveFanoutLocked(from, to V) {
+	moved := m.fanoutOrder[from]
+	delete(m.fanoutOrder, from)
+	keys := m.fanoutOrder[to]
+	if keys == nil {
+		m.fanoutOrder[to] = moved
+		return
+	}
+	for e := moved.Front(); e != nil; e = e.Next() {
+		key := e.Value.(K)
+		m.fanoutElems[key] = keys.PushBack(key)
+	}
+	for keys.Len() > m.fanoutLimit {
+		m.evictKeyLocked(keys.Front().Value.(K))
+	}
+}
+
+// syncFanoutLocked reconciles the per-value key age order with the data
+// after WithLock changed it directly. Keys that still hold the same value
+// keep their position; the others are appended as the newest keys of their
+// current value.
+// This is an internal method and assumes the caller holds the write lock.
+func (m *Map[K, V]) syncFanoutLocked() {
+	for v, keys := range m.fanoutOrder {
+		for e := keys.Front(); e != nil; {
+			next := e.Next()
+			key := e.Value.(K)
+			if dv, ok := m.data[key]; !ok || dv != v {
+				keys.Remove(e)
+				delete(m.fanoutElems, key)
+			}
+			e = next
+		}
+		if keys.Len() == 0 {
+			delete(m.fanoutOrder, v)
+		}
+	}
+	for k, v := range m.data {
+		if _, ok := m.fanoutElems[k]; !ok {
+			m.trackFanoutLocked(k, v)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package genericmap
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+func TestMaxKeysPerValueReject(t *testing.T) {
+	m := NewWithMaxKeysPerValue[string, int](2, FanoutReject)
+
+	if !m.Offer("a", 1) || !m.Offer("b", 1) {
+		t.Fatalf("Expected keys up to the limit to be accepted")
+	}
+	if m.Offer("c", 1) {
+		t.Errorf("Expected a third key for value 1 to be refused")
+	}
+	if m.Contains("c") || m.CountKeys(1) != 2 {
+		t.Errorf("Expected refused key to be absent from both indexes")
+	}
+
+	// Moving an existing key onto a full value is refused too
+	m.Set("d", 2)
+	m.Set("d", 1)
+	if val, _ := m.Get("d"); val != 2 {
+		t.Errorf("Expected d to keep value 2, got %d", val)
+	}
+	if _, stored := m.SetReport("e", 1); stored || m.SetIfAbsent("e", 1) {
+		t.Errorf("Expected SetReport and SetIfAbsent to report the refusal")
+	}
+	if m.Replace("d", 1) || m.CompareAndSwap("d", 2, 1) || m.TrySet("d", 1) {
+		t.Errorf("Expected Replace, CompareAndSwap and TrySet to report the refusal")
+	}
+	if old, moved := m.Move("d", 1); moved || old != 2 {
+		t.Errorf("Expected Move to report the refusal, got (%v, %v)", old, moved)
+	}
+
+	// Rewriting a key with its current value is always allowed
+	if !m.Offer("a", 1) {
+		t.Errorf("Expected no-op write to be accepted")
+	}
+
+	// Room frees up after a removal
+	m.Remove("a")
+	if !m.Offer("c", 1) {
+		t.Errorf("Expected insert to succeed after a removal")
+	}
+
+	// SwapValue refuses to merge past the limit
+	if n := m.SwapValue(2, 1); n != 0 || m.CountKeys(2) != 1 {
+		t.Errorf("Expected SwapValue to retag nothing, got %d", n)
+	}
+	if n := m.SwapValue(2, 3); n != 1 {
+		t.Errorf("Expected SwapValue into a free value to retag 1 key, got %d", n)
+	}
+	if err := m.CheckIntegrity(); err != nil {
+		t.Errorf("Expected consistent map, got %v", err)
+	}
+}
+
+func TestMaxKeysPerValueEvictOldest(t *testing.T) {
+	m := NewWithMaxKeysPerValue[string, int](2, FanoutEvictOldest)
+	var evicted []string
+	m.OnEvict(func(key string, value int) {
+		evicted = append(evicted, fmt.Sprint(key, "=", value))
+	})
+
+	m.Set("a", 1)
+	m.Set("b", 1)
+	m.Set("c", 1)
+	if m.Contains("a") {
+		t.Errorf("Expected oldest key a to be evicted")
+	}
+	keys := m.GetKeys(1)
+	sort.Strings(keys)
+	if fmt.Sprint(keys) != "[b c]" {
+		t.Errorf("Expected keys [b c] for value 1, got %v", keys)
+	}
+
+	// A key that leaves and rejoins a value counts as new
+	m.Set("b", 2)
+	m.Set("b", 1)
+	m.Set("d", 1)
+	if m.Contains("c") || !m.Contains("b") {
+		t.Errorf("Expected c to be evicted before the rejoined b")
+	}
+
+	// Merged key sets keep the retagged keys as the newest
+	m.Set("x", 3)
+	m.Set("y", 3)
+	if n := m.SwapValue(3, 1); n != 2 {
+		t.Errorf("Expected SwapValue to retag 2 keys, got %d", n)
+	}
+	keys = m.GetKeys(1)
+	sort.Strings(keys)
+	if fmt.Sprint(keys) != "[x y]" {
+		t.Errorf("Expected keys [x y] for value 1, got %v", keys)
+	}
+
+	if fmt.Sprint(evicted) != "[a=1 c=1 b=1 d=1]" {
+		t.Errorf("Expected evictions [a=1 c=1 b=1 d=1], got %v", evicted)
+	}
+	if err := m.CheckIntegrity(); err != nil {
+		t.Errorf("Expected consistent map, got %v", err)
+	}
+
+	m.Clear()
+	m.Set("a", 1)
+	m.RebuildIndex()
+	if err := m.CheckIntegrity(); err != nil {
+		t.Errorf("Expected consistent map after Clear and RebuildIndex, got %v", err)
+	}
+}
+
+func TestMaxKeysPerValueWithLock(t *testing.T) {
+	m := NewWithMaxKeysPerValue[string, int](2, FanoutEvictOldest)
+	m.Set("a", 1)
+	m.Set("b", 2)
+
+	// Move b onto value 1 and add c directly, keeping the indexes consistent
+	m.WithLock(func(data map[string]int, reverse map[int]map[string]struct{}) {
+		data["b"] = 1
+		delete(reverse, 2)
+		reverse[1]["b"] = struct{}{}
+		data["c"] = 3
+		reverse[3] = map[string]struct{}{"c": {}}
+	})
+	if err := m.CheckIntegrity(); err != nil {
+		t.Fatalf("Expected consistent map after WithLock, got %v", err)
+	}
+
+	// a is still the oldest key of value 1
+	m.Set("d", 1)
+	if m.Contains("a") || !m.Contains("b") {
+		t.Errorf("Expected a to be evicted before b, got %v", m.List())
+	}
+	m.Set("e", 3)
+	m.Set("f", 3)
+	if m.Contains("c") {
+		t.Errorf("Expected c to be evicted as the oldest key of value 3")
+	}
+	if err := m.CheckIntegrity(); err != nil {
+		t.Errorf("Expected consistent map, got %v", err)
+	}
+}
+
+func TestNewWithMaxKeysPerValueInvalidLimit(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected NewWithMaxKeysPerValue(0) to panic")
+		}
+	}()
+	NewWithMaxKeysPerValue[string, int](0, FanoutReject)
+}
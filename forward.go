@@ -0,0 +1,21 @@
+package genericmap
+
+// NewForwardOnly creates a new generic map that does not keep a reverse
+// index, turning it into a plain thread-safe map. Writes skip all
+// reverse-index maintenance, which roughly halves their cost and memory.
+//
+// Methods that need the reverse index, such as GetKeys, ContainsValue,
+// CountKeys, RemoveValue or Invert, panic on such a map rather than
+// silently returning an empty result.
+func NewForwardOnly[K comparable, V comparable]() *Map[K, V] {
+	m := newMap[K, V](0)
+	m.forward = true
+	return m
+}
+
+// requireReverse panics if the map was created with NewForwardOnly.
+func (m *Map[K, V]) requireReverse(method string) {
+	if m.forward {
+		panic("genericmap: " + method + " requires the reverse index, which maps created with NewForwardOnly do not keep")
+	}
+}
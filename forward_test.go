@@ -0,0 +1,55 @@
+package genericmap
+
+import "testing"
+
+func TestNewForwardOnly(t *testing.T) {
+	m := NewForwardOnly[string, int]()
+
+	m.Set("a", 1)
+	m.Set("b", 1)
+	m.Set("a", 2)
+	m.Remove("b")
+
+	if val, ok := m.Get("a"); !ok || val != 2 {
+		t.Errorf("Expected a=2, got %v, exists: %v", val, ok)
+	}
+	if m.Len() != 1 {
+		t.Errorf("Expected length 1, got %d", m.Len())
+	}
+	if len(m.reverseMap) != 0 {
+		t.Errorf("Expected no reverse index entries, got %d", len(m.reverseMap))
+	}
+	if err := m.CheckIntegrity(); err != nil {
+		t.Errorf("Expected forward-only map to pass CheckIntegrity, got %v", err)
+	}
+	m.RebuildIndex()
+	if len(m.reverseMap) != 0 {
+		t.Errorf("Expected RebuildIndex to leave the reverse index empty")
+	}
+}
+
+func TestNewForwardOnlyReverseMethodsPanic(t *testing.T) {
+	m := NewForwardOnly[string, int]()
+	m.Set("a", 1)
+
+	calls := map[string]func(){
+		"GetKeys":       func() { m.GetKeys(1) },
+		"ContainsValue": func() { m.ContainsValue(1) },
+		"CountKeys":     func() { m.CountKeys(1) },
+		"RemoveValue":   func() { m.RemoveValue(1) },
+		"Invert":        func() { Invert(m) },
+		"Tx.GetKeys": func() {
+			m.Transaction(func(tx *Tx[string, int]) { tx.GetKeys(1) })
+		},
+	}
+	for name, call := range calls {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected %s to panic on a forward-only map", name)
+				}
+			}()
+			call()
+		}()
+	}
+}
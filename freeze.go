@@ -0,0 +1,42 @@
+package genericmap
+
+// Freeze makes the map permanently immutable. Afterwards every mutating
+// method, such as Set, Remove, Clear or Transaction, panics, and SetContext
+// returns ErrFrozen. Registering OnChange and OnEvict callbacks is still
+// allowed, though they will never fire again.
+//
+// Since a frozen map can no longer change, Get and Contains read it without
+// taking the lock, which makes it cheap to share widely for read-heavy use
+// such as configuration. Other reads keep their usual locking.
+//
+// Freeze also stops the sweeper of a map created with NewWithTTL. Entries
+// that expire after freezing are reported as absent but never removed, and
+// an LRU map no longer tracks recency. Freeze is safe to call more than
+// once.
+func (m *Map[K, V]) Freeze() {
+	if traceEnabled {
+		defer traceStart("Freeze")()
+	}
+	m.mu.Lock()
+	m.frozen.Store(true)
+	m.unlock()
+
+	m.Close()
+}
+
+// IsFrozen reports whether Freeze has been called on the map.
+func (m *Map[K, V]) IsFrozen() bool {
+	if traceEnabled {
+		defer traceStart("IsFrozen")()
+	}
+	return m.frozen.Load()
+}
+
+// checkFrozenLocked releases the write lock and panics if the map is frozen.
+// Write paths call it right after acquiring the lock.
+func (m *Map[K, V]) checkFrozenLocked() {
+	if m.frozen.Load() {
+		m.mu.Unlock()
+		panic("genericmap: mutation of a frozen map")
+	}
+}
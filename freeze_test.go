@@ -0,0 +1,174 @@
+package genericmap
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFreeze(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 2})
+	m.Freeze()
+	m.Freeze() // idempotent
+
+	if !m.IsFrozen() {
+		t.Errorf("Expected map to report frozen")
+	}
+	if val, ok := m.Get("a"); !ok || val != 1 {
+		t.Errorf("Expected reads to work on a frozen map, got %v, exists: %v", val, ok)
+	}
+	if !m.Contains("b") || m.Contains("z") || m.Len() != 2 || m.CountKeys(2) != 1 {
+		t.Errorf("Expected lookups to reflect the frozen contents")
+	}
+
+	mutations := map[string]func(){
+		"Set":         func() { m.Set("c", 3) },
+		"Remove":      func() { m.Remove("a") },
+		"Clear":       func() { m.Clear() },
+		"TrySet":      func() { m.TrySet("c", 3) },
+		"Transaction": func() { m.Transaction(func(*Tx[string, int]) {}) },
+	}
+	for name, mutate := range mutations {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected %s to panic on a frozen map", name)
+				}
+			}()
+			mutate()
+		}()
+	}
+
+	if err := m.SetContext(context.Background(), "c", 3); !errors.Is(err, ErrFrozen) {
+		t.Errorf("Expected ErrFrozen from SetContext, got %v", err)
+	}
+
+	// The lock is released after a refused mutation
+	if m.Len() != 2 {
+		t.Errorf("Expected contents unchanged, got length %d", m.Len())
+	}
+}
+
+func TestFreezeConcurrentReads(t *testing.T) {
+	m := New[int, int]()
+	for i := 0; i < 100; i++ {
+		m.Set(i, i)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				m.Get(i % 100)
+				m.Contains(i % 100)
+			}
+		}()
+	}
+	m.Freeze()
+	wg.Wait()
+}
+
+func TestFreezeConcurrentLRUReads(t *testing.T) {
+	// LRU and expiring reads take the write lock, so Freeze can win the
+	// race for it; those reads must still not panic
+	for run := 0; run < 50; run++ {
+		m := NewLRU[int, int](100)
+		for i := 0; i < 100; i++ {
+			m.Set(i, i)
+		}
+
+		var wg, started sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			started.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 2000; i++ {
+					if i == 1 {
+						started.Done()
+					}
+					if val, ok := m.Get(i % 100); !ok || val != i%100 {
+						t.Errorf("Expected %d, got %v, exists: %v", i%100, val, ok)
+						return
+					}
+				}
+			}()
+		}
+		started.Wait()
+		m.Freeze()
+		wg.Wait()
+	}
+}
+
+func TestFreezeWhileSetContextWaits(t *testing.T) {
+	m := New[string, int]()
+
+	// Hold the lock so that SetContext has to wait for it, then freeze the
+	// map before letting it through
+	m.mu.Lock()
+	done := make(chan error)
+	go func() {
+		done <- m.SetContext(context.Background(), "a", 1)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	m.frozen.Store(true)
+	m.mu.Unlock()
+
+	if err := <-done; !errors.Is(err, ErrFrozen) {
+		t.Errorf("Expected ErrFrozen once the map froze, got %v", err)
+	}
+	if m.Len() != 0 {
+		t.Errorf("Expected frozen map to stay empty, got length %d", m.Len())
+	}
+}
+
+func TestFreezeTwoMapWrites(t *testing.T) {
+	// other gets the lower id, so lockTwo locks it before the frozen map
+	other := New[string, int](map[string]int{"a": 1})
+	frozen := New[string, int]()
+	frozen.Freeze()
+
+	mutations := map[string]func(){
+		"Merge":    func() { frozen.Merge(other) },
+		"CopyInto": func() { other.CopyInto(frozen) },
+	}
+	for name, mutate := range mutations {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected %s into a frozen map to panic", name)
+				}
+			}()
+			mutate()
+		}()
+
+		// Both locks are released after the refused mutation
+		if !other.TrySet("b", 2) {
+			t.Errorf("Expected other to be unlocked after %s panicked", name)
+		}
+		if !frozen.mu.TryRLock() {
+			t.Errorf("Expected frozen map to be unlocked after %s panicked", name)
+		} else {
+			frozen.mu.RUnlock()
+		}
+	}
+}
+
+func TestFreezeWithTTL(t *testing.T) {
+	m := NewWithTTL[string, int](10 * time.Millisecond)
+	defer m.Close()
+	m.Set("a", 1)
+	m.Freeze()
+
+	time.Sleep(30 * time.Millisecond)
+	if _, ok := m.Get("a"); ok {
+		t.Errorf("Expected expired entry to be reported absent")
+	}
+	if m.Len() != 1 {
+		t.Errorf("Expected expired entry to be kept in a frozen map, got length %d", m.Len())
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/costa92/genericmap
 
-go 1.20
+go 1.24
@@ -0,0 +1,88 @@
+package genericmap
+
+// Op identifies the kind of mutation described by a ChangeEvent.
+type Op int
+
+const (
+	// OpSet reports that a key was inserted or its value changed.
+	OpSet Op = iota + 1
+	// OpRemove reports that a key was removed, including by expiration.
+	OpRemove
+)
+
+// String returns the name of the operation.
+func (o Op) String() string {
+	switch o {
+	case OpSet:
+		return "Set"
+	case OpRemove:
+		return "Remove"
+	default:
+		return "Unknown"
+	}
+}
+
+// ChangeEvent describes a single mutation of a Map.
+type ChangeEvent[K comparable, V comparable] struct {
+	Op       Op
+	Key      K
+	OldValue V    // previous value, valid only if Existed is true
+	NewValue V    // stored value, valid only for OpSet
+	Existed  bool // whether the key was present before the mutation
+}
+
+// OnChange registers fn to be called after every mutation of the map.
+//
+// Events are recorded while the write lock is held but fn is only invoked
+// after the lock has been released, on the goroutine that performed the
+// mutation and before the mutating method returns. fn may therefore call
+// back into the map without deadlocking.
+//
+// Ordering: the events of a single method call are delivered in the order
+// the changes were applied, and a goroutine sees the events of its own calls
+// in call order. Events caused by concurrent mutations on different
+// goroutines may be delivered in any relative order. Setting a key to the
+// value it already holds is not a change and produces no event.
+func (m *Map[K, V]) OnChange(fn func(event ChangeEvent[K, V])) {
+	if traceEnabled {
+		defer traceStart("OnChange")()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hooks = append(m.hooks, fn)
+}
+
+// lock acquires the write lock. Every mutating method must pair it with
+// unlock so that recorded change events are delivered. It panics if the
+// map is frozen.
+func (m *Map[K, V]) lock() {
+	m.mu.Lock()
+	m.checkFrozenLocked()
+}
+
+// unlock releases the write lock and then delivers the change events and
+// evictions recorded while it was held.
+func (m *Map[K, V]) unlock() {
+	events, hooks := m.pending, m.hooks
+	evicted, evictHooks := m.evicted, m.evictHooks
+	m.pending, m.evicted = nil, nil
+	m.mu.Unlock()
+
+	for _, event := range events {
+		for _, fn := range hooks {
+			fn(event)
+		}
+	}
+	for _, p := range evicted {
+		for _, fn := range evictHooks {
+			fn(p.Key, p.Value)
+		}
+	}
+}
+
+// record queues an event for delivery by unlock.
+// This is an internal method and assumes the caller holds the write lock.
+func (m *Map[K, V]) record(event ChangeEvent[K, V]) {
+	m.pending = append(m.pending, event)
+}
@@ -0,0 +1,85 @@
+package genericmap
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestOnChange(t *testing.T) {
+	m := New[string, int]()
+
+	var events []ChangeEvent[string, int]
+	m.OnChange(func(e ChangeEvent[string, int]) {
+		events = append(events, e)
+	})
+
+	m.Set("a", 1)
+	m.Set("a", 1) // no-op, no event
+	m.Set("a", 2)
+	m.Remove("a")
+	m.Remove("missing") // no-op, no event
+
+	expected := []ChangeEvent[string, int]{
+		{Op: OpSet, Key: "a", NewValue: 1},
+		{Op: OpSet, Key: "a", OldValue: 1, NewValue: 2, Existed: true},
+		{Op: OpRemove, Key: "a", OldValue: 2, Existed: true},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %d: %v", len(expected), len(events), events)
+	}
+	for i, e := range expected {
+		if events[i] != e {
+			t.Errorf("Event %d: expected %+v, got %+v", i, e, events[i])
+		}
+	}
+}
+
+func TestOnChangeBulkOperations(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 2})
+
+	var removed []string
+	m.OnChange(func(e ChangeEvent[string, int]) {
+		if e.Op == OpRemove {
+			removed = append(removed, e.Key)
+		}
+	})
+
+	m.Clear()
+	sort.Strings(removed)
+	if len(removed) != 2 || removed[0] != "a" || removed[1] != "b" {
+		t.Errorf("Expected Clear to report removal of [a b], got %v", removed)
+	}
+}
+
+func TestOnChangeReentrant(t *testing.T) {
+	m := New[string, int]()
+
+	// The callback may call back into the map without deadlocking
+	m.OnChange(func(e ChangeEvent[string, int]) {
+		if e.Op == OpSet && e.Key == "source" {
+			m.Set("mirror", e.NewValue)
+		}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		m.Set("source", 7)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Set deadlocked while running a re-entrant callback")
+	}
+
+	if val, ok := m.Get("mirror"); !ok || val != 7 {
+		t.Errorf("Expected mirror=7 from callback, got %v, exists: %v", val, ok)
+	}
+}
+
+func TestOpString(t *testing.T) {
+	if OpSet.String() != "Set" || OpRemove.String() != "Remove" || Op(0).String() != "Unknown" {
+		t.Errorf("Unexpected Op names: %v %v %v", OpSet, OpRemove, Op(0))
+	}
+}
@@ -0,0 +1,129 @@
+package genericmap
+
+import "fmt"
+
+// RebuildIndex discards the reverse index and reconstructs it from the
+// forward data. The forward data is the source of truth, so this repairs
+// any drift between the two. It is mainly a debugging and recovery aid.
+func (m *Map[K, V]) RebuildIndex() {
+	if traceEnabled {
+		defer traceStart("RebuildIndex")()
+	}
+	m.lock()
+	defer m.unlock()
+
+	if m.forward {
+		return
+	}
+	reverseMap := make(map[V]map[K]struct{}, len(m.reverseMap))
+	for k, v := range m.data {
+		if reverseMap[v] == nil {
+			reverseMap[v] = make(map[K]struct{}, m.keysPerValue)
+		}
+		reverseMap[v][k] = struct{}{}
+	}
+	m.reverseMap = reverseMap
+
+	// Key age is not recoverable, so each value's keys start over in
+	// arbitrary order
+	if m.fanoutOrder != nil {
+		clear(m.fanoutOrder)
+		clear(m.fanoutElems)
+		for k, v := range m.data {
+			m.trackFanoutLocked(k, v)
+		}
+	}
+}
+
+// CheckIntegrity verifies that the map's derived state is consistent with
+// its forward data. It checks that:
+//
+//   - every (key, value) pair is present in the reverse index under value;
+//   - every key in the reverse index maps back to that value in the data;
+//   - no value in the reverse index has an empty key set;
+//   - expiration deadlines, insertion order and the sorted key index only
+//     track existing keys;
+//   - on a map created with NewWithMaxKeysPerValue, no value exceeds the
+//     limit and the per-value key age order matches the reverse index.
+//
+// It returns an error wrapping ErrInconsistentIndex that describes the first
+// inconsistency found, or nil. The map is read-locked during the check,
+// which is O(n); it is meant for tests and debug-mode assertions.
+func (m *Map[K, V]) CheckIntegrity() error {
+	if traceEnabled {
+		defer traceStart("CheckIntegrity")()
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.checkIntegrityLocked()
+}
+
+// checkIntegrityLocked implements CheckIntegrity.
+// This is an internal method and assumes the caller holds the lock.
+func (m *Map[K, V]) checkIntegrityLocked() error {
+	if m.forward && len(m.reverseMap) > 0 {
+		return fmt.Errorf("%w: forward-only map has %d reverse index entries", ErrInconsistentIndex, len(m.reverseMap))
+	}
+	for k, v := range m.data {
+		if _, ok := m.reverseMap[v][k]; !ok && !m.forward {
+			return fmt.Errorf("%w: key %v with value %v is missing from the reverse index", ErrInconsistentIndex, k, v)
+		}
+	}
+	for v, keyMap := range m.reverseMap {
+		if len(keyMap) == 0 {
+			return fmt.Errorf("%w: value %v has an empty key set", ErrInconsistentIndex, v)
+		}
+		for k := range keyMap {
+			dv, ok := m.data[k]
+			if !ok {
+				return fmt.Errorf("%w: reverse index maps value %v to missing key %v", ErrInconsistentIndex, v, k)
+			}
+			if dv != v {
+				return fmt.Errorf("%w: reverse index maps value %v to key %v, which holds %v", ErrInconsistentIndex, v, k, dv)
+			}
+		}
+	}
+	for k := range m.expires {
+		if _, ok := m.data[k]; !ok {
+			return fmt.Errorf("%w: expiration deadline for missing key %v", ErrInconsistentIndex, k)
+		}
+	}
+	if m.compare != nil {
+		if len(m.sortedKeys) != len(m.data) {
+			return fmt.Errorf("%w: key index tracks %d keys, map holds %d", ErrInconsistentIndex, len(m.sortedKeys), len(m.data))
+		}
+		for i, k := range m.sortedKeys {
+			if _, ok := m.data[k]; !ok {
+				return fmt.Errorf("%w: key index holds missing key %v", ErrInconsistentIndex, k)
+			}
+			if i > 0 && m.compare(m.sortedKeys[i-1], k) >= 0 {
+				return fmt.Errorf("%w: key index is not sorted at %v", ErrInconsistentIndex, k)
+			}
+		}
+	}
+	if m.order != nil {
+		if m.order.Len() != len(m.data) || len(m.elems) != len(m.data) {
+			return fmt.Errorf("%w: insertion order tracks %d keys, map holds %d", ErrInconsistentIndex, m.order.Len(), len(m.data))
+		}
+		for k := range m.elems {
+			if _, ok := m.data[k]; !ok {
+				return fmt.Errorf("%w: insertion order holds missing key %v", ErrInconsistentIndex, k)
+			}
+		}
+	}
+	if m.fanoutOrder != nil {
+		if len(m.fanoutElems) != len(m.data) {
+			return fmt.Errorf("%w: key age order tracks %d keys, map holds %d", ErrInconsistentIndex, len(m.fanoutElems), len(m.data))
+		}
+		for v, keyMap := range m.reverseMap {
+			if len(keyMap) > m.fanoutLimit {
+				return fmt.Errorf("%w: value %v has %d keys, limit is %d", ErrInconsistentIndex, v, len(keyMap), m.fanoutLimit)
+			}
+			if keys := m.fanoutOrder[v]; keys == nil || keys.Len() != len(keyMap) {
+				return fmt.Errorf("%w: key age order of value %v does not match its key set", ErrInconsistentIndex, v)
+			}
+		}
+	}
+	return nil
+}
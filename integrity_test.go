@@ -0,0 +1,127 @@
+package genericmap
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRebuildIndex(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 2, "c": 1})
+	if err := m.CheckIntegrity(); err != nil {
+		t.Fatalf("Expected consistent index, got %v", err)
+	}
+
+	// Corrupt the reverse index in both directions
+	m.mu.Lock()
+	delete(m.reverseMap[1], "a")
+	m.reverseMap[9] = map[string]struct{}{"ghost": {}}
+	m.mu.Unlock()
+	if err := m.CheckIntegrity(); err == nil {
+		t.Fatalf("Expected CheckIntegrity to detect corruption")
+	}
+
+	m.RebuildIndex()
+	if err := m.CheckIntegrity(); err != nil {
+		t.Errorf("Expected consistent index after rebuild, got %v", err)
+	}
+	if m.ContainsValue(9) {
+		t.Errorf("Expected stale value 9 to be dropped")
+	}
+	if n := m.CountKeys(1); n != 2 {
+		t.Errorf("Expected 2 keys for value 1 after rebuild, got %d", n)
+	}
+}
+
+func TestCheckIntegrity(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(m *Map[string, int])
+		want    string
+	}{
+		{
+			name:    "missing reverse entry",
+			corrupt: func(m *Map[string, int]) { delete(m.reverseMap[1], "a") },
+			want:    "key a with value 1 is missing",
+		},
+		{
+			name:    "reverse entry for missing key",
+			corrupt: func(m *Map[string, int]) { m.reverseMap[2]["ghost"] = struct{}{} },
+			want:    "missing key ghost",
+		},
+		{
+			name: "reverse entry with wrong value",
+			corrupt: func(m *Map[string, int]) {
+				m.reverseMap[2]["a"] = struct{}{}
+			},
+			want: "which holds 1",
+		},
+		{
+			name:    "empty key set",
+			corrupt: func(m *Map[string, int]) { m.reverseMap[7] = map[string]struct{}{} },
+			want:    "value 7 has an empty key set",
+		},
+		{
+			name: "stale deadline",
+			corrupt: func(m *Map[string, int]) {
+				m.expires = map[string]time.Time{"ghost": time.Now()}
+			},
+			want: "deadline for missing key ghost",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New[string, int](map[string]int{"a": 1, "b": 2})
+			tt.corrupt(m)
+
+			err := m.CheckIntegrity()
+			if !errors.Is(err, ErrInconsistentIndex) {
+				t.Fatalf("Expected ErrInconsistentIndex, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error to mention %q, got %q", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestCheckIntegrityOrdered(t *testing.T) {
+	m := NewOrdered[string, int]()
+	m.Set("a", 1)
+	m.Set("b", 2)
+	if err := m.CheckIntegrity(); err != nil {
+		t.Fatalf("Expected consistent ordered map, got %v", err)
+	}
+
+	delete(m.elems, "a")
+	if err := m.CheckIntegrity(); !errors.Is(err, ErrInconsistentIndex) {
+		t.Errorf("Expected ErrInconsistentIndex for broken order, got %v", err)
+	}
+}
+
+func TestCheckIntegrityAfterOperations(t *testing.T) {
+	m := New[int, int]()
+	for i := 0; i < 100; i++ {
+		m.Set(i, i%7)
+	}
+	for i := 0; i < 100; i += 3 {
+		m.Set(i, i%5)
+	}
+	m.RemoveValue(3)
+	m.RemoveIf(func(k, _ int) bool { return k%4 == 0 })
+	m.Pop(1)
+	m.Merge(New[int, int](map[int]int{200: 1, 2: 6}))
+	m.CompareAndSwap(5, 0, 9)
+	m.CompareAndDelete(6, 6)
+	m.Update(7, func(v int, _ bool) int { return v + 10 })
+	m.Transaction(func(tx *Tx[int, int]) {
+		tx.Remove(9)
+		tx.Set(300, 0)
+	})
+
+	if err := m.CheckIntegrity(); err != nil {
+		t.Errorf("Expected consistent index after mixed operations, got %v", err)
+	}
+}
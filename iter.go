@@ -0,0 +1,122 @@
+package genericmap
+
+import "iter"
+
+// All returns an iterator over the key-value pairs in the map, for use with
+// range-over-func:
+//
+//	for k, v := range m.All() {
+//		fmt.Println(k, v)
+//	}
+//
+// Each iteration works on a snapshot of the map taken under the read lock when
+// the loop starts. The lock is not held while the loop body runs, so the body
+// may freely call any method on the map; changes made during the loop are not
+// reflected in the ongoing iteration. Iteration order is unspecified.
+func (m *Map[K, V]) All() iter.Seq2[K, V] {
+	if traceEnabled {
+		defer traceStart("All")()
+	}
+	return func(yield func(K, V) bool) {
+		keys, values := m.snapshot()
+		for i := range keys {
+			if !yield(keys[i], values[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns an iterator over the keys in the map.
+// It follows the same snapshot semantics as All.
+func (m *Map[K, V]) Keys() iter.Seq[K] {
+	if traceEnabled {
+		defer traceStart("Keys")()
+	}
+	return func(yield func(K) bool) {
+		for _, k := range m.List() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// ValuesSeq returns an iterator over the values in the map, one per key.
+// It follows the same snapshot semantics as All. Use Values to get the
+// values as a slice instead.
+func (m *Map[K, V]) ValuesSeq() iter.Seq[V] {
+	if traceEnabled {
+		defer traceStart("ValuesSeq")()
+	}
+	return func(yield func(V) bool) {
+		for _, v := range m.Values() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Iterator is a pull-style iterator over a snapshot of a Map, obtained from
+// Map.Iterator. It does not hold the map's lock, so the map may be read or
+// modified freely during iteration; such changes are not reflected. An
+// Iterator is not safe for concurrent use by multiple goroutines.
+//
+// Example:
+//
+//	it := m.Iterator()
+//	for it.Next() {
+//		fmt.Println(it.Key(), it.Value())
+//	}
+type Iterator[K comparable, V comparable] struct {
+	keys   []K
+	values []V
+	pos    int
+}
+
+// Iterator returns an Iterator over a snapshot of the map taken under the
+// read lock. Iteration order is unspecified.
+func (m *Map[K, V]) Iterator() *Iterator[K, V] {
+	if traceEnabled {
+		defer traceStart("Iterator")()
+	}
+	keys, values := m.snapshot()
+	return &Iterator[K, V]{keys: keys, values: values, pos: -1}
+}
+
+// Next advances the iterator to the next entry and reports whether there
+// is one. It must be called before the first call to Key or Value.
+func (it *Iterator[K, V]) Next() bool {
+	if it.pos < len(it.keys) {
+		it.pos++
+	}
+	return it.pos < len(it.keys)
+}
+
+// Key returns the key of the current entry. It panics if Next has not been
+// called or has returned false.
+func (it *Iterator[K, V]) Key() K {
+	return it.keys[it.pos]
+}
+
+// Value returns the value of the current entry. It panics if Next has not
+// been called or has returned false.
+func (it *Iterator[K, V]) Value() V {
+	return it.values[it.pos]
+}
+
+// snapshot copies all pairs under the read lock into parallel slices,
+// so that keys[i] maps to values[i].
+func (m *Map[K, V]) snapshot() ([]K, []V) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := make([]K, 0, len(m.data))
+	values := make([]V, 0, len(m.data))
+	for k, v := range m.data {
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+	return keys, values
+}
@@ -0,0 +1,72 @@
+package genericmap
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+	initial := map[string]int{"a": 1, "b": 2, "c": 3}
+	m := New[string, int](initial)
+
+	if got := maps.Collect(m.All()); !maps.Equal(got, initial) {
+		t.Errorf("Expected %v from All, got %v", initial, got)
+	}
+
+	// Early break
+	count := 0
+	for range m.All() {
+		count++
+		break
+	}
+	if count != 1 {
+		t.Errorf("Expected loop to stop after 1 iteration, got %d", count)
+	}
+
+	// Mutating the map inside the loop must not deadlock
+	for k := range m.All() {
+		m.Remove(k)
+	}
+	if m.Len() != 0 {
+		t.Errorf("Expected empty map after removing inside loop, got length %d", m.Len())
+	}
+}
+
+func TestKeysAndValuesSeq(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 2, "c": 1})
+
+	keys := slices.Sorted(m.Keys())
+	if !slices.Equal(keys, []string{"a", "b", "c"}) {
+		t.Errorf("Expected keys [a b c], got %v", keys)
+	}
+
+	values := slices.Sorted(m.ValuesSeq())
+	if !slices.Equal(values, []int{1, 1, 2}) {
+		t.Errorf("Expected values [1 1 2], got %v", values)
+	}
+}
+
+func TestIterator(t *testing.T) {
+	initial := map[string]int{"a": 1, "b": 2, "c": 1}
+	m := New[string, int](initial)
+
+	it := m.Iterator()
+	got := make(map[string]int)
+	for it.Next() {
+		got[it.Key()] = it.Value()
+		// Mutating the map while iterating neither deadlocks nor changes the snapshot
+		m.Set("added-"+it.Key(), 0)
+	}
+	if !maps.Equal(got, initial) {
+		t.Errorf("Expected %v from Iterator, got %v", initial, got)
+	}
+	if it.Next() {
+		t.Errorf("Expected Next to keep returning false once exhausted")
+	}
+
+	empty := New[string, int]().Iterator()
+	if empty.Next() {
+		t.Errorf("Expected Next to return false on an empty map")
+	}
+}
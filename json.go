@@ -0,0 +1,128 @@
+package genericmap
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// jsonPair is the JSON form of an entry when keys are not strings.
+type jsonPair[K any, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+// WriteJSON streams the map to w as JSON, encoding one entry at a time so
+// the whole document is never held in memory. Maps with string keys are
+// written as a JSON object; any other key type is written as an array of
+// {"key": ..., "value": ...} objects. Only the forward data is written.
+//
+// The entries are copied under the read lock and written after it has been
+// released, so slow writers do not block the map. The output reflects the
+// map at the moment of the copy.
+func (m *Map[K, V]) WriteJSON(w io.Writer) error {
+	if traceEnabled {
+		defer traceStart("WriteJSON")()
+	}
+	keys, values := m.snapshot()
+	stringKeys := reflect.TypeFor[K]().Kind() == reflect.String
+
+	bw := bufio.NewWriter(w)
+	start, end := byte('['), byte(']')
+	if stringKeys {
+		start, end = '{', '}'
+	}
+	bw.WriteByte(start)
+	for i := range keys {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		var entry []byte
+		var err error
+		if stringKeys {
+			entry, err = json.Marshal(keys[i])
+			if err == nil {
+				entry = append(entry, ':')
+				var value []byte
+				value, err = json.Marshal(values[i])
+				entry = append(entry, value...)
+			}
+		} else {
+			entry, err = json.Marshal(jsonPair[K, V]{Key: keys[i], Value: values[i]})
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := bw.Write(entry); err != nil {
+			return err
+		}
+	}
+	bw.WriteByte(end)
+	return bw.Flush()
+}
+
+// ReadJSON replaces the contents of the map with the entries read from r,
+// in the format produced by WriteJSON. Entries are decoded one at a time
+// rather than reading the whole document first. The map is only modified,
+// under a single write lock and rebuilding the reverse index, once the
+// input has been decoded successfully; on error it is left unchanged. If a
+// key appears more than once, the last occurrence wins.
+func (m *Map[K, V]) ReadJSON(r io.Reader) error {
+	if traceEnabled {
+		defer traceStart("ReadJSON")()
+	}
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	data := make(map[K]V)
+	switch tok {
+	case json.Delim('{'):
+		if reflect.TypeFor[K]().Kind() != reflect.String {
+			return fmt.Errorf("genericmap: cannot read JSON object into map with %v keys", reflect.TypeFor[K]())
+		}
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			var key K
+			reflect.ValueOf(&key).Elem().SetString(tok.(string))
+			var value V
+			if err := dec.Decode(&value); err != nil {
+				return err
+			}
+			data[key] = value
+		}
+	case json.Delim('['):
+		for dec.More() {
+			var pair jsonPair[K, V]
+			if err := dec.Decode(&pair); err != nil {
+				return err
+			}
+			data[pair.Key] = pair.Value
+		}
+	default:
+		return fmt.Errorf("genericmap: expected JSON object or array, got %v", tok)
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+
+	m.lock()
+	defer m.unlock()
+
+	if m.data == nil {
+		m.initLocked(len(data))
+	} else {
+		m.clearLocked()
+	}
+	for k, v := range data {
+		m.setLocked(m.normKey(k), v)
+	}
+	return nil
+}
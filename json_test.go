@@ -0,0 +1,95 @@
+package genericmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestWriteJSONStringKeys(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 2, "c": 1})
+
+	var buf bytes.Buffer
+	if err := m.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+
+	var decoded map[string]int
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Expected a JSON object, got %s: %v", buf.String(), err)
+	}
+	if len(decoded) != 3 || decoded["a"] != 1 || decoded["b"] != 2 || decoded["c"] != 1 {
+		t.Errorf("Unexpected JSON content: %s", buf.String())
+	}
+
+	other := New[string, int](map[string]int{"stale": 9})
+	if err := other.ReadJSON(&buf); err != nil {
+		t.Fatalf("ReadJSON failed: %v", err)
+	}
+	if !other.Equal(m) {
+		t.Errorf("Expected round trip to equal original, got %v", other)
+	}
+	keys := other.GetKeys(1)
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "c" {
+		t.Errorf("Expected rebuilt reverse index keys [a c] for value 1, got %v", keys)
+	}
+}
+
+func TestWriteJSONNonStringKeys(t *testing.T) {
+	m := New[int, string](map[int]string{1: "x", 2: "y"})
+
+	var buf bytes.Buffer
+	if err := m.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "[") {
+		t.Errorf("Expected an array of pairs, got %s", buf.String())
+	}
+
+	var decoded Map[int, string]
+	if err := decoded.ReadJSON(&buf); err != nil {
+		t.Fatalf("ReadJSON failed: %v", err)
+	}
+	if !decoded.Equal(m) {
+		t.Errorf("Expected round trip to equal original, got %v", &decoded)
+	}
+
+	// Empty maps round trip too
+	buf.Reset()
+	if err := New[int, string]().WriteJSON(&buf); err != nil || buf.String() != "[]" {
+		t.Errorf("Expected [] for empty map, got %q, %v", buf.String(), err)
+	}
+}
+
+type failingWriter struct{}
+
+var errWrite = errors.New("write failed")
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errWrite }
+
+func TestWriteJSONErrors(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1})
+	if err := m.WriteJSON(failingWriter{}); !errors.Is(err, errWrite) {
+		t.Errorf("Expected writer error, got %v", err)
+	}
+}
+
+func TestReadJSONInvalid(t *testing.T) {
+	m := New[string, int](map[string]int{"keep": 1})
+
+	for _, input := range []string{`"text"`, `{"a": "not a number"}`, `{"a": 1`, ``} {
+		if err := m.ReadJSON(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected error for input %q", input)
+		}
+	}
+	if err := New[int, int]().ReadJSON(strings.NewReader(`{"1": 1}`)); err == nil {
+		t.Errorf("Expected error reading an object into a map with int keys")
+	}
+	if val, ok := m.Get("keep"); !ok || val != 1 {
+		t.Errorf("Expected map unchanged after failed reads, got %v, exists: %v", val, ok)
+	}
+}
@@ -0,0 +1,68 @@
+package genericmap
+
+import "container/list"
+
+// NewLRU creates a new generic map holding at most maxSize entries. When an
+// insert would exceed maxSize, the least recently used key is evicted first,
+// from both the forward data and the reverse index. It panics if maxSize is
+// not positive.
+//
+// A key counts as used when it is written or read with Get (including
+// Tx.Get). Other lookups, such as Contains, GetOr or reverse lookups, do not
+// refresh recency. OrderedList reports keys least recently used first.
+//
+// Because Get records the access, it takes the write lock on an LRU map
+// rather than the read lock.
+func NewLRU[K comparable, V comparable](maxSize int) *Map[K, V] {
+	if maxSize <= 0 {
+		panic("genericmap: NewLRU requires a positive maxSize")
+	}
+	m := newMap[K, V](maxSize)
+	m.order = list.New()
+	m.elems = make(map[K]*list.Element, maxSize)
+	m.maxSize = maxSize
+	return m
+}
+
+// OnEvict registers fn to be called for every entry evicted to make room
+// in a map created with NewLRU, or with NewWithMaxKeysPerValue and
+// FanoutEvictOldest. Like OnChange callbacks, fn runs after the
+// write lock has been released, on the goroutine whose insert caused the
+// eviction, so it may call back into the map. Evictions are also reported
+// to OnChange callbacks as OpRemove events.
+func (m *Map[K, V]) OnEvict(fn func(key K, value V)) {
+	if traceEnabled {
+		defer traceStart("OnEvict")()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.evictHooks = append(m.evictHooks, fn)
+}
+
+// touchLocked marks the key as most recently used.
+// This is an internal method and assumes the caller holds the write lock.
+func (m *Map[K, V]) touchLocked(key K) {
+	if e, ok := m.elems[key]; ok {
+		m.order.MoveToBack(e)
+	}
+}
+
+// evictLocked removes the least recently used entry.
+// This is an internal method and assumes the caller holds the write lock.
+func (m *Map[K, V]) evictLocked() {
+	front := m.order.Front()
+	if front == nil {
+		return
+	}
+	m.evictKeyLocked(front.Value.(K))
+}
+
+// evictKeyLocked removes the key and queues it for the OnEvict callbacks.
+// This is an internal method and assumes the caller holds the write lock.
+func (m *Map[K, V]) evictKeyLocked(key K) {
+	value, _ := m.deleteLocked(key)
+	if len(m.evictHooks) > 0 {
+		m.evicted = append(m.evicted, Pair[K, V]{Key: key, Value: value})
+	}
+}
@@ -0,0 +1,82 @@
+package genericmap
+
+import "testing"
+
+func TestNewLRU(t *testing.T) {
+	m := NewLRU[string, int](3)
+
+	var evicted []Pair[string, int]
+	m.OnEvict(func(key string, value int) {
+		evicted = append(evicted, Pair[string, int]{Key: key, Value: value})
+	})
+
+	m.Set("a", 1)
+	m.Set("b", 1)
+	m.Set("c", 2)
+
+	// Reading a makes b the least recently used
+	m.Get("a")
+	m.Set("d", 3)
+
+	if m.Len() != 3 {
+		t.Errorf("Expected length 3, got %d", m.Len())
+	}
+	if m.Contains("b") {
+		t.Errorf("Expected b to be evicted")
+	}
+	if len(evicted) != 1 || evicted[0].Key != "b" || evicted[0].Value != 1 {
+		t.Errorf("Expected eviction of b=1, got %v", evicted)
+	}
+	if keys := m.GetKeys(1); len(keys) != 1 || keys[0] != "a" {
+		t.Errorf("Expected reverse index to drop b, got %v", keys)
+	}
+
+	// Updating c refreshes it, so a is evicted next
+	m.Set("c", 4)
+	m.Set("e", 5)
+	if m.Contains("a") {
+		t.Errorf("Expected a to be evicted")
+	}
+
+	order := m.OrderedList()
+	expected := []string{"d", "c", "e"}
+	if len(order) != len(expected) {
+		t.Fatalf("Expected recency order %v, got %v", expected, order)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Errorf("Expected recency order %v, got %v", expected, order)
+			break
+		}
+	}
+	if err := m.CheckIntegrity(); err != nil {
+		t.Errorf("Expected consistent map, got %v", err)
+	}
+}
+
+func TestNewLRUEvictCallbackReentrant(t *testing.T) {
+	m := NewLRU[int, int](1)
+	other := New[int, int]()
+
+	// The callback runs after the lock is released and may use the map
+	m.OnEvict(func(key int, value int) {
+		other.Set(key, value)
+		m.Len()
+	})
+
+	m.Set(1, 10)
+	m.Set(2, 20)
+
+	if val, ok := other.Get(1); !ok || val != 10 {
+		t.Errorf("Expected evicted 1=10 to be flushed, got %v, exists: %v", val, ok)
+	}
+}
+
+func TestNewLRUInvalidSize(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected NewLRU(0) to panic")
+		}
+	}()
+	NewLRU[string, int](0)
+}
@@ -3,20 +3,83 @@
 package genericmap
 
 import (
+	"container/list"
 	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// mapIDs hands out a unique id to every map, used to order lock acquisition
+// when an operation needs to lock two maps at once.
+var mapIDs atomic.Uint64
+
 // Map is a thread-safe, generic map with bidirectional lookup capabilities.
 // It supports both key-to-value and value-to-keys operations efficiently.
 type Map[K comparable, V comparable] struct {
-	data       map[K]V
-	reverseMap map[V]map[K]struct{}
-	mu         sync.RWMutex
+	data         map[K]V
+	reverseMap   map[V]map[K]struct{}
+	mu           sync.RWMutex
+	id           uint64
+	peak         int           // largest size reached since allocation, see Cap
+	forward      bool          // reverseMap is not maintained, see NewForwardOnly
+	normalize    func(K) K     // applied to incoming keys, see NewWithKeyNormalizer
+	keysPerValue int           // initial size of each reverse-index key set, see NewWithReverseHint
+	frozen       atomic.Bool   // set once by Freeze, never cleared
+	version      atomic.Uint64 // bumped under the write lock by every mutation, see Version
+
+	// Expiration state, see NewWithTTL
+	ttl      time.Duration
+	expires  map[K]time.Time // nil unless entries can expire
+	stop     chan struct{}   // closed by Close to stop the sweeper
+	stopOnce sync.Once
+
+	// Insertion order, see NewOrdered
+	order *list.List          // keys oldest-first, nil unless ordered
+	elems map[K]*list.Element // position of each key in order
+
+	// Sorted key index, see NewWithPrefixIndex
+	sortedKeys []K              // ascending, maintained only if compare is set
+	compare    func(a, b K) int // nil unless the key index is kept
+	hasPrefix  func(key, prefix K) bool
+
+	// Result ordering, see NewSorted
+	sortKeys   func([]K) // nil unless key slices are returned sorted
+	sortValues func([]V) // nil unless value slices are returned sorted
+
+	// Recency eviction state, see NewLRU
+	bound      int // 0 unless inserts past it are refused, see NewBounded
+	maxSize    int // 0 unless the map evicts; order then runs least recent first
+	evictHooks []func(key K, value V)
+	evicted    []Pair[K, V] // recorded under the write lock, delivered by unlock
+
+	// Per-value key limit, see NewWithMaxKeysPerValue
+	fanoutLimit  int // 0 unless key sets are capped
+	fanoutPolicy FanoutPolicy
+	fanoutOrder  map[V]*list.List    // keys of each value, oldest first
+	fanoutElems  map[K]*list.Element // position of each key in its value's list
+
+	metrics Metrics   // never nil, NopMetrics unless configured
+	keyPool sync.Pool // *[]K buffers, see ListPooled
+
+	// Change notification state, see OnChange
+	hooks   []func(ChangeEvent[K, V])
+	pending []ChangeEvent[K, V] // recorded under the write lock, delivered by unlock
+}
+
+// Pair is a single key-value pair.
+type Pair[K any, V any] struct {
+	Key   K
+	Value V
 }
 
 // New creates a new generic map with optional initial data.
 //
+// When several initial maps are supplied they are merged in argument order,
+// and a key that appears in more than one map takes its value from the last
+// map that contains it. Nil maps are skipped.
+//
 // Examples:
 //
 //	// Create empty map
@@ -26,151 +89,1943 @@ type Map[K comparable, V comparable] struct {
 //	initial := map[string]int{"a": 1, "b": 2}
 //	m := New[string, int](initial)
 func New[K comparable, V comparable](initialData ...map[K]V) *Map[K, V] {
-	m := &Map[K, V]{
-		data:       make(map[K]V),
-		reverseMap: make(map[V]map[K]struct{}),
-	}
-
-	// Populate with initial data if provided
-	if len(initialData) > 0 {
-		for _, dataMap := range initialData {
-			for k, v := range dataMap {
-				m.data[k] = v
-				if m.reverseMap[v] == nil {
-					m.reverseMap[v] = make(map[K]struct{})
-				}
-				m.reverseMap[v][k] = struct{}{}
-			}
+	m := newMap[K, V](0)
+
+	// Populate with initial data if provided, later maps win on conflicts
+	for _, dataMap := range initialData {
+		if dataMap == nil {
+			continue
+		}
+		for k, v := range dataMap {
+			m.setLocked(k, v)
 		}
 	}
 
 	return m
 }
 
+// NewStrict creates a new generic map from several initial maps like New,
+// but refuses to pick a winner when they disagree: if a key appears in more
+// than one map with different values, no map is built and an error wrapping
+// ErrKeyConflict that names the key and both values is returned. A key
+// repeated with the same value is not a conflict. Nil maps are skipped.
+func NewStrict[K comparable, V comparable](maps ...map[K]V) (*Map[K, V], error) {
+	m := newMap[K, V](0)
+	for _, dataMap := range maps {
+		for k, v := range dataMap {
+			if old, exists := m.data[k]; exists && old != v {
+				return nil, fmt.Errorf("%w: %v is set to both %v and %v", ErrKeyConflict, k, old, v)
+			}
+			m.setLocked(k, v)
+		}
+	}
+	return m, nil
+}
+
 // NewWithCapacity creates a new generic map with specified initial capacity.
 // This can improve performance when the expected size is known in advance.
 func NewWithCapacity[K comparable, V comparable](capacity int) *Map[K, V] {
+	return newMap[K, V](capacity)
+}
+
+// NewWithReverseHint creates a new generic map for workloads where each
+// value accumulates many keys. Every per-value key set in the reverse index
+// is allocated with room for keysPerValue keys, instead of starting small
+// and rehashing repeatedly as keys are added. A hint of zero or less
+// behaves like New.
+func NewWithReverseHint[K comparable, V comparable](keysPerValue int) *Map[K, V] {
+	m := newMap[K, V](0)
+	m.keysPerValue = max(keysPerValue, 0)
+	return m
+}
+
+// NewWithCapacityAndData creates a new generic map preallocated for capacity
+// entries and populated with data. Sizing the storage up front avoids the
+// rehashing that happens when a large dataset is loaded into a default-sized
+// map. If data holds more entries than capacity, its length is used instead.
+func NewWithCapacityAndData[K comparable, V comparable](capacity int, data map[K]V) *Map[K, V] {
+	if len(data) > capacity {
+		capacity = len(data)
+	}
+	m := newMap[K, V](capacity)
+	for k, v := range data {
+		m.setLocked(k, v)
+	}
+	return m
+}
+
+// NewUnique creates a new generic map populated with data, which must be a
+// one-to-one mapping. If two or more keys share a value, no map is built and
+// an error wrapping ErrDuplicateValue that names the shared value is
+// returned instead. Use it for data that is meant to be inverted later, or
+// where a repeated value indicates a bug; New accepts shared values.
+func NewUnique[K comparable, V comparable](data map[K]V) (*Map[K, V], error) {
+	m := newMap[K, V](len(data))
+	for k, v := range data {
+		m.setLocked(k, v)
+	}
+	for v, keyMap := range m.reverseMap {
+		if len(keyMap) > 1 {
+			return nil, fmt.Errorf("%w: %v is mapped by %d keys", ErrDuplicateValue, v, len(keyMap))
+		}
+	}
+	return m, nil
+}
+
+// NewFromMap creates a new, independent map holding a copy of src's
+// entries, with its own reverse index. The copy is taken from a consistent
+// snapshot under a single read lock on src. Only the live contents are
+// copied: expired entries are left out, and the new map is a plain map
+// without deadlines regardless of how src was constructed.
+func NewFromMap[K comparable, V comparable](src *Map[K, V]) *Map[K, V] {
+	src.mu.RLock()
+	defer src.mu.RUnlock()
+
+	m := newMap[K, V](len(src.data))
+	for k, v := range src.data {
+		if !src.expiredLocked(k) {
+			m.setLocked(k, v)
+		}
+	}
+	return m
+}
+
+// newMap allocates an empty map sized for capacity entries.
+func newMap[K comparable, V comparable](capacity int) *Map[K, V] {
 	return &Map[K, V]{
 		data:       make(map[K]V, capacity),
 		reverseMap: make(map[V]map[K]struct{}, capacity),
+		id:         mapIDs.Add(1),
+		peak:       capacity,
+		metrics:    NopMetrics{},
+	}
+}
+
+// initLocked prepares a zero Map value for use, so that decoders can target
+// a Map that was not created by a constructor. It is a no-op for maps that
+// are already initialized. This is an internal method and assumes the
+// caller holds the write lock.
+func (m *Map[K, V]) initLocked(capacity int) {
+	if m.data != nil {
+		return
 	}
+	m.data = make(map[K]V, capacity)
+	m.reverseMap = make(map[V]map[K]struct{}, capacity)
+	m.id = mapIDs.Add(1)
+	m.metrics = NopMetrics{}
 }
 
 // Set adds or updates a key-value pair in the map.
 func (m *Map[K, V]) Set(key K, value V) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	if traceEnabled {
+		defer traceStart("Set")()
+	}
+	key = m.normKey(key)
+	m.lock()
+	defer m.unlock()
 
-	// Single lookup to check existing value
+	m.setLocked(key, value)
+}
+
+// SetReport adds or updates a key-value pair like Set and reports what
+// happened. stored is false only if the map refused the write, as described
+// for Offer, in which case nothing changed and inserted is false too.
+// Otherwise inserted reports whether the key was new: it is false when an
+// existing value was replaced, including when the key already held an equal
+// value and the set was a no-op, so every stored call counts as exactly one
+// insert or one update.
+//
+// stored is a separate result because inserted alone cannot describe a
+// refusal: false would read as an update, and true as an insert that never
+// happened. Callers of maps that never refuse, such as those made by New,
+// can ignore it.
+func (m *Map[K, V]) SetReport(key K, value V) (inserted, stored bool) {
+	if traceEnabled {
+		defer traceStart("SetReport")()
+	}
+	key = m.normKey(key)
+	m.lock()
+	defer m.unlock()
+
+	m.expireLocked(key)
+	_, exists := m.data[key]
+	stored = m.setLocked(key, value)
+	return stored && !exists, stored
+}
+
+// SetMany adds or updates all key-value pairs from items under a single
+// write lock. It is considerably cheaper than calling Set in a loop when
+// the batch is large or the map is contended.
+func (m *Map[K, V]) SetMany(items map[K]V) {
+	if traceEnabled {
+		defer traceStart("SetMany")()
+	}
+	m.lock()
+	defer m.unlock()
+
+	for k, v := range items {
+		m.setLocked(m.normKey(k), v)
+	}
+}
+
+// SetPairs adds or updates keys[i] -> values[i] for every index under a
+// single write lock, avoiding the intermediate map that SetMany would need
+// for columnar input. If a key repeats within keys, the last occurrence
+// wins. It returns an error wrapping ErrLengthMismatch, and leaves the map
+// unchanged, when the slices differ in length.
+func (m *Map[K, V]) SetPairs(keys []K, values []V) error {
+	if traceEnabled {
+		defer traceStart("SetPairs")()
+	}
+	if len(keys) != len(values) {
+		return fmt.Errorf("%w: %d keys, %d values", ErrLengthMismatch, len(keys), len(values))
+	}
+
+	m.lock()
+	defer m.unlock()
+
+	for i, k := range keys {
+		m.setLocked(m.normKey(k), values[i])
+	}
+	return nil
+}
+
+// SetAll adds or updates every pair under a single write lock. It accepts
+// the Pair type returned by Entries, so entries can be copied, filtered or
+// transformed between maps without converting to a native map. If a key
+// repeats within pairs, the last occurrence wins.
+func (m *Map[K, V]) SetAll(pairs []Pair[K, V]) {
+	if traceEnabled {
+		defer traceStart("SetAll")()
+	}
+	m.lock()
+	defer m.unlock()
+
+	for _, p := range pairs {
+		m.setLocked(m.normKey(p.Key), p.Value)
+	}
+}
+
+// SetValidated inserts the entries of items that pass validate, under a
+// single write lock so the batch is not interleaved with other writers.
+// Entries for which validate returns an error are skipped and reported in
+// failures, keyed by their key. It returns the number of entries inserted;
+// entries the map refuses, as described for Offer, count as neither
+// inserted nor failed. The write lock is held while validate runs, so
+// validate must not call any method on the map.
+func (m *Map[K, V]) SetValidated(items map[K]V, validate func(key K, value V) error) (inserted int, failures map[K]error) {
+	if traceEnabled {
+		defer traceStart("SetValidated")()
+	}
+	m.lock()
+	defer m.unlock()
+
+	failures = make(map[K]error)
+	for k, v := range items {
+		k = m.normKey(k)
+		if err := validate(k, v); err != nil {
+			failures[k] = err
+			continue
+		}
+		if m.setLocked(k, v) {
+			inserted++
+		}
+	}
+	return inserted, failures
+}
+
+// ApplyPatch applies a batch of changes as one atomic step: readers see
+// either none or all of it. The keys in remove are deleted first, then the
+// entries in set are stored, so a key listed in both ends up set to its
+// new value. It returns the number of mutations that took effect: removals
+// of present keys plus stores that inserted a key or changed its value.
+func (m *Map[K, V]) ApplyPatch(set map[K]V, remove []K) (applied int) {
+	if traceEnabled {
+		defer traceStart("ApplyPatch")()
+	}
+	m.lock()
+	defer m.unlock()
+
+	for _, key := range remove {
+		key = m.normKey(key)
+		m.expireLocked(key)
+		if _, ok := m.deleteLocked(key); ok {
+			applied++
+		}
+	}
+	for key, value := range set {
+		key = m.normKey(key)
+		m.expireLocked(key)
+		old, exists := m.data[key]
+		if m.setLocked(key, value) && (!exists || old != value) {
+			applied++
+		}
+	}
+	return applied
+}
+
+// Merge copies every entry from other into the map.
+//
+// Conflict resolution: keys present in both maps take the value from other,
+// keys present only in the receiver are left untouched. Entries that have
+// expired in other are skipped. The receiver is write-locked and other
+// read-locked for the whole operation, in a consistent order, so concurrent
+// a.Merge(b) and b.Merge(a) calls cannot deadlock.
+func (m *Map[K, V]) Merge(other *Map[K, V]) {
+	if traceEnabled {
+		defer traceStart("Merge")()
+	}
+	if other == nil || other == m {
+		return
+	}
+	unlock := lockTwo(m, other, true)
+	defer unlock()
+
+	for k, v := range other.data {
+		if !other.expiredLocked(k) {
+			m.setLocked(m.normKey(k), v)
+		}
+	}
+}
+
+// CopyInto replaces the contents of dst with a copy of m, reusing the
+// storage dst has already allocated. It is the allocation-free counterpart
+// of NewFromMap for snapshot loops that recycle destination maps. Entries
+// are stored as if by Set on dst, so the reverse index is rebuilt from
+// scratch and dst's own modes, such as a size bound or TTL, still apply.
+//
+// m is read-locked and dst write-locked in the same global order as Merge,
+// so concurrent copies between the same maps cannot deadlock.
+func (m *Map[K, V]) CopyInto(dst *Map[K, V]) {
+	if traceEnabled {
+		defer traceStart("CopyInto")()
+	}
+	if dst == nil || dst == m {
+		return
+	}
+	unlock := lockTwo(dst, m, true)
+	defer unlock()
+
+	dst.clearLocked()
+	for k, v := range m.data {
+		if !m.expiredLocked(k) {
+			dst.setLocked(dst.normKey(k), v)
+		}
+	}
+}
+
+// GetOrSet returns the existing value for the key if present.
+// Otherwise, it stores the given value and returns it.
+// The loaded result is true if the value was loaded, false if stored.
+// The check and the insert happen under a single write lock, matching
+// the semantics of sync.Map.LoadOrStore.
+//
+// If the map refuses the insert, as described for Offer, nothing is stored
+// and GetOrSet returns the zero value and false, which cannot be told apart
+// from storing a zero value. On such maps, use GetOrOffer instead.
+func (m *Map[K, V]) GetOrSet(key K, value V) (actual V, loaded bool) {
+	if traceEnabled {
+		defer traceStart("GetOrSet")()
+	}
+	key = m.normKey(key)
+	m.lock()
+	defer m.unlock()
+
+	m.expireLocked(key)
+	if existing, exists := m.data[key]; exists {
+		return existing, true
+	}
+	if !m.setLocked(key, value) {
+		var zero V
+		return zero, false
+	}
+	return value, false
+}
+
+// SetIfAbsent stores the value only if the key is not already present.
+// Returns true if the value was inserted, false if the key already existed
+// or the map refused the insert, as described for Offer. An existing entry
+// and its reverse index are left untouched.
+func (m *Map[K, V]) SetIfAbsent(key K, value V) bool {
+	if traceEnabled {
+		defer traceStart("SetIfAbsent")()
+	}
+	key = m.normKey(key)
+	m.lock()
+	defer m.unlock()
+
+	m.expireLocked(key)
+	if _, exists := m.data[key]; exists {
+		return false
+	}
+	return m.setLocked(key, value)
+}
+
+// Replace updates the value for the key only if the key already exists.
+// Returns true if the value was replaced, false if the key was absent, in
+// which case nothing is inserted, or if the map refused the write, as
+// described for Offer.
+func (m *Map[K, V]) Replace(key K, value V) bool {
+	if traceEnabled {
+		defer traceStart("Replace")()
+	}
+	key = m.normKey(key)
+	m.lock()
+	defer m.unlock()
+
+	m.expireLocked(key)
+	if _, exists := m.data[key]; !exists {
+		return false
+	}
+	return m.setLocked(key, value)
+}
+
+// Swap stores the value for the key and returns the previous value, if any.
+// The loaded result reports whether the key was present. When it was not,
+// Swap returns the zero value and false and still performs the insert.
+// If the map refuses the write, as described for Offer, the map is left
+// unchanged and Swap still reports the key's current state; on such maps,
+// use Offer when the outcome matters.
+func (m *Map[K, V]) Swap(key K, value V) (previous V, loaded bool) {
+	if traceEnabled {
+		defer traceStart("Swap")()
+	}
+	key = m.normKey(key)
+	m.lock()
+	defer m.unlock()
+
+	m.expireLocked(key)
+	previous, loaded = m.data[key]
+	m.setLocked(key, value)
+	return previous, loaded
+}
+
+// Move reassigns the key to newValue and returns the value it held before.
+// The moved result is true only if the key existed and its value actually
+// changed. A missing key is inserted, reporting the zero value and false;
+// an existing key that already holds newValue is left as is and also
+// reports false, as does a write the map refuses, as described for Offer.
+func (m *Map[K, V]) Move(key K, newValue V) (oldValue V, moved bool) {
+	if traceEnabled {
+		defer traceStart("Move")()
+	}
+	key = m.normKey(key)
+	m.lock()
+	defer m.unlock()
+
+	m.expireLocked(key)
 	oldValue, exists := m.data[key]
-	if exists && oldValue == value {
-		return // No-op if key already has this value
+	stored := m.setLocked(key, newValue)
+	return oldValue, stored && exists && oldValue != newValue
+}
+
+// SwapValue retags every key that maps to oldValue so that it maps to
+// newValue instead, merging with any keys newValue already has. It returns
+// the number of keys retagged, which is 0 if oldValue is absent or equal to
+// newValue.
+//
+// The whole key set is moved in the reverse index in one step under a
+// single write lock, so readers never observe a partial rename. Unlike Set,
+// SwapValue leaves expiration deadlines and LRU recency unchanged.
+func (m *Map[K, V]) SwapValue(oldValue, newValue V) int {
+	if traceEnabled {
+		defer traceStart("SwapValue")()
 	}
+	m.requireReverse("SwapValue")
+	m.lock()
+	defer m.unlock()
 
-	// Remove key from old value's reverse map if key exists
-	if exists {
-		m.removeFromReverseMap(key, oldValue)
+	keyMap, ok := m.reverseMap[oldValue]
+	if !ok || oldValue == newValue {
+		return 0
+	}
+	if m.fanoutLimit > 0 && m.fanoutPolicy == FanoutReject && len(keyMap)+len(m.reverseMap[newValue]) > m.fanoutLimit {
+		return 0
+	}
+	retagged := len(keyMap)
+	m.version.Add(1)
+	delete(m.reverseMap, oldValue)
+	for key := range keyMap {
+		m.data[key] = newValue
+		m.metrics.IncSet()
+		if len(m.hooks) > 0 {
+			m.record(ChangeEvent[K, V]{Op: OpSet, Key: key, OldValue: oldValue, NewValue: newValue, Existed: true})
+		}
 	}
 
-	// Add to data and reverse maps
-	m.data[key] = value
-	if m.reverseMap[value] == nil {
-		m.reverseMap[value] = make(map[K]struct{})
+	// Keep the larger set and fold the smaller one into it
+	target := m.reverseMap[newValue]
+	if len(target) < len(keyMap) {
+		target, keyMap = keyMap, target
+	}
+	for key := range keyMap {
+		target[key] = struct{}{}
+	}
+	m.reverseMap[newValue] = target
+	if m.fanoutOrder != nil {
+		m.moveFanoutLocked(oldValue, newValue)
+	}
+	return retagged
+}
+
+// CompareAndSwap stores the new value for the key only if the key exists and
+// its current value equals old. Returns true if the swap was performed;
+// otherwise, including when the map refuses the write as described for
+// Offer, the map is left unchanged.
+func (m *Map[K, V]) CompareAndSwap(key K, old, new V) bool {
+	if traceEnabled {
+		defer traceStart("CompareAndSwap")()
+	}
+	key = m.normKey(key)
+	m.lock()
+	defer m.unlock()
+
+	m.expireLocked(key)
+	if current, exists := m.data[key]; !exists || current != old {
+		return false
+	}
+	return m.setLocked(key, new)
+}
+
+// Update atomically replaces the value for the key with the result of fn.
+// fn receives the current value and whether the key exists; for a missing
+// key it is called with the zero value and exists=false, and whatever it
+// returns is inserted. The write lock is held while fn runs, so fn must not
+// call any method on the map.
+//
+// Example:
+//
+//	m.Update("hits", func(v int, _ bool) int { return v + 1 })
+func (m *Map[K, V]) Update(key K, fn func(old V, exists bool) V) {
+	if traceEnabled {
+		defer traceStart("Update")()
 	}
-	m.reverseMap[value][key] = struct{}{}
+	key = m.normKey(key)
+	m.lock()
+	defer m.unlock()
+
+	m.expireLocked(key)
+	old, exists := m.data[key]
+	m.setLocked(key, fn(old, exists))
 }
 
 // Get retrieves the value associated with the key.
 // Returns the value and a boolean indicating if the key exists.
 func (m *Map[K, V]) Get(key K) (V, bool) {
+	if traceEnabled {
+		defer traceStart("Get")()
+	}
+	key = m.normKey(key)
+	if m.frozen.Load() {
+		return m.getFrozen(key)
+	}
+	if m.maxSize > 0 {
+		// Recording the access needs the write lock. It is taken directly
+		// rather than with lock, so that a concurrent Freeze turns this into
+		// a plain read instead of a panic.
+		m.mu.Lock()
+		if m.frozen.Load() {
+			m.mu.Unlock()
+			return m.getFrozen(key)
+		}
+		m.expireLocked(key)
+		val, ok := m.data[key]
+		if ok {
+			m.touchLocked(key)
+		}
+		m.unlock()
+		m.metrics.IncGet(ok)
+		return val, ok
+	}
+
 	m.mu.RLock()
-	defer m.mu.RUnlock()
+	val, ok := m.data[key]
+	expired := ok && m.expiredLocked(key)
+	m.mu.RUnlock()
+
+	if expired {
+		// Lazily drop the stale entry under the write lock, unless the map
+		// has been frozen in the meantime
+		m.mu.Lock()
+		if !m.frozen.Load() {
+			m.expireLocked(key)
+		}
+		m.unlock()
+		var zero V
+		ok, val = false, zero
+	}
+	m.metrics.IncGet(ok)
+	return val, ok
+}
 
+// getFrozen looks up the key in a frozen map, which is immutable and can
+// therefore be read without locking.
+func (m *Map[K, V]) getFrozen(key K) (V, bool) {
 	val, ok := m.data[key]
+	if ok && m.expiredLocked(key) {
+		var zero V
+		val, ok = zero, false
+	}
+	m.metrics.IncGet(ok)
 	return val, ok
 }
 
+// GetMany looks up several keys under a single read lock and returns a
+// native map holding the ones that are present. Missing keys are simply
+// absent from the result, which is keyed by the keys as passed in, even on
+// a map with a key normalizer. All lookups observe the same state of the map.
+func (m *Map[K, V]) GetMany(keys ...K) map[K]V {
+	if traceEnabled {
+		defer traceStart("GetMany")()
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make(map[K]V, len(keys))
+	for _, key := range keys {
+		stored := m.normKey(key)
+		val, ok := m.data[stored]
+		ok = ok && !m.expiredLocked(stored)
+		if ok {
+			result[key] = val
+		}
+		m.metrics.IncGet(ok)
+	}
+	return result
+}
+
+// GetOr returns the value associated with the key, or def if the key is
+// not present.
+func (m *Map[K, V]) GetOr(key K, def V) V {
+	if traceEnabled {
+		defer traceStart("GetOr")()
+	}
+	key = m.normKey(key)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if val, ok := m.data[key]; ok && !m.expiredLocked(key) {
+		m.metrics.IncGet(true)
+		return val
+	}
+	m.metrics.IncGet(false)
+	return def
+}
+
+// Contains reports whether the key exists in the map.
+func (m *Map[K, V]) Contains(key K) bool {
+	if traceEnabled {
+		defer traceStart("Contains")()
+	}
+	key = m.normKey(key)
+	if !m.frozen.Load() {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+	}
+
+	_, ok := m.data[key]
+	return ok && !m.expiredLocked(key)
+}
+
+// ContainsValue reports whether at least one key maps to the value.
+// It is the value-side mirror of Contains: a single reverse-index lookup
+// that does not allocate and, on maps without deadlines, runs in O(1), so
+// prefer it over testing len(m.GetKeys(value)). Expired keys do not count.
+func (m *Map[K, V]) ContainsValue(value V) bool {
+	if traceEnabled {
+		defer traceStart("ContainsValue")()
+	}
+	m.requireReverse("ContainsValue")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, ok := m.liveKeyLocked(m.reverseMap[value])
+	return ok
+}
+
 // GetKeys retrieves all keys associated with a given value.
 // Returns a slice of keys that map to the specified value.
 func (m *Map[K, V]) GetKeys(value V) []K {
+	if traceEnabled {
+		defer traceStart("GetKeys")()
+	}
+	m.requireReverse("GetKeys")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keyMap := m.reverseMap[value]
+	m.metrics.ObserveReverseFanout(len(keyMap))
+	result := make([]K, 0, len(keyMap))
+	for key := range keyMap {
+		if !m.expiredLocked(key) {
+			result = append(result, key)
+		}
+	}
+	m.orderKeys(result)
+	return result
+}
+
+// GetKeysMany retrieves the keys associated with each of the given values,
+// keyed by value. Values with no keys are omitted. Every slice is freshly
+// allocated, and all lookups happen under a single read lock, so the result
+// is a consistent snapshot across values, unlike calling GetKeys in a loop.
+func (m *Map[K, V]) GetKeysMany(values ...V) map[V][]K {
+	if traceEnabled {
+		defer traceStart("GetKeysMany")()
+	}
+	m.requireReverse("GetKeysMany")
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	if keyMap, ok := m.reverseMap[value]; ok {
-		result := make([]K, 0, len(keyMap))
+	result := make(map[V][]K, len(values))
+	for _, v := range values {
+		keyMap := m.reverseMap[v]
+		m.metrics.ObserveReverseFanout(len(keyMap))
+		if len(keyMap) == 0 {
+			continue
+		}
+		keys := make([]K, 0, len(keyMap))
 		for key := range keyMap {
+			if !m.expiredLocked(key) {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		m.orderKeys(keys)
+		result[v] = keys
+	}
+	return result
+}
+
+// GetKeysOr retrieves all keys associated with the value, or def if no key
+// maps to it. It is the reverse-side counterpart of GetOr. The keys are
+// copied into a new slice as in GetKeys, but def is returned as is, not
+// copied, so callers that modify the result must not share def.
+func (m *Map[K, V]) GetKeysOr(value V, def []K) []K {
+	if traceEnabled {
+		defer traceStart("GetKeysOr")()
+	}
+	m.requireReverse("GetKeysOr")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keyMap := m.reverseMap[value]
+	m.metrics.ObserveReverseFanout(len(keyMap))
+	result := make([]K, 0, len(keyMap))
+	for key := range keyMap {
+		if !m.expiredLocked(key) {
 			result = append(result, key)
 		}
-		return result
 	}
-	return []K{}
+	if len(result) == 0 {
+		return def
+	}
+	m.orderKeys(result)
+	return result
 }
 
-// List returns all keys in the map.
-func (m *Map[K, V]) List() []K {
+// GetKeysInto appends all keys associated with the value to buf[:0] and
+// returns the resulting slice. Reusing the same buffer across calls avoids
+// allocating a new slice for every lookup; buf only grows when it is too
+// small. Callers must use the returned slice, which may have been
+// reallocated.
+func (m *Map[K, V]) GetKeysInto(value V, buf []K) []K {
+	if traceEnabled {
+		defer traceStart("GetKeysInto")()
+	}
+	m.requireReverse("GetKeysInto")
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	keys := make([]K, len(m.data))
-	i := 0
-	for k := range m.data {
-		keys[i] = k
-		i++
+	buf = buf[:0]
+	m.metrics.ObserveReverseFanout(len(m.reverseMap[value]))
+	for key := range m.reverseMap[value] {
+		if !m.expiredLocked(key) {
+			buf = append(buf, key)
+		}
+	}
+	m.orderKeys(buf)
+	return buf
+}
+
+// GetKeysSorted retrieves all keys associated with the value, sorted
+// according to less. The same map state always yields the same order,
+// which makes the result suitable for display.
+func (m *Map[K, V]) GetKeysSorted(value V, less func(a, b K) bool) []K {
+	if traceEnabled {
+		defer traceStart("GetKeysSorted")()
 	}
+	keys := m.GetKeys(value)
+	sort.Slice(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
 	return keys
 }
 
-// Values returns all values in the map.
-func (m *Map[K, V]) Values() []V {
+// GetKeysFunc retrieves the keys associated with the value for which pred
+// returns true. Filtering happens during the lookup, under a single read
+// lock, so only matching keys are allocated. The read lock is held while
+// pred runs, so pred must not modify the map.
+func (m *Map[K, V]) GetKeysFunc(value V, pred func(key K) bool) []K {
+	if traceEnabled {
+		defer traceStart("GetKeysFunc")()
+	}
+	m.requireReverse("GetKeysFunc")
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	values := make([]V, len(m.data))
-	i := 0
-	for _, v := range m.data {
-		values[i] = v
-		i++
+	keyMap := m.reverseMap[value]
+	m.metrics.ObserveReverseFanout(len(keyMap))
+	result := []K{}
+	for key := range keyMap {
+		if !m.expiredLocked(key) && pred(key) {
+			result = append(result, key)
+		}
 	}
-	return values
+	m.orderKeys(result)
+	return result
 }
 
-// Remove removes a key-value pair from the map.
-// Returns true if the key existed and was removed, false otherwise.
-func (m *Map[K, V]) Remove(key K) bool {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+// KeysWhere returns the keys whose values satisfy pred. Unlike GetKeys,
+// which is an O(1) exact-value lookup in the reverse index, KeysWhere must
+// scan every entry and runs in O(n). The read lock is held while pred runs,
+// so pred must not modify the map.
+func (m *Map[K, V]) KeysWhere(pred func(value V) bool) []K {
+	if traceEnabled {
+		defer traceStart("KeysWhere")()
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 
-	if value, exists := m.data[key]; exists {
-		delete(m.data, key)
-		m.removeFromReverseMap(key, value)
-		return true
+	result := []K{}
+	for k, v := range m.data {
+		if !m.expiredLocked(k) && pred(v) {
+			result = append(result, k)
+		}
 	}
-	return false
+	m.orderKeys(result)
+	return result
 }
 
-// Len returns the number of key-value pairs in the map.
-func (m *Map[K, V]) Len() int {
+// GetAny returns an arbitrary key associated with the given value.
+// The boolean is false if no key maps to the value. It avoids building the
+// full key slice when any single key will do.
+func (m *Map[K, V]) GetAny(value V) (K, bool) {
+	if traceEnabled {
+		defer traceStart("GetAny")()
+	}
+	m.requireReverse("GetAny")
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	return len(m.data)
+	return m.liveKeyLocked(m.reverseMap[value])
 }
 
-// String returns a string representation of the map.
-func (m *Map[K, V]) String() string {
+// CountKeys returns the number of keys associated with the given value,
+// not counting expired ones. It does not allocate, unlike
+// len(m.GetKeys(value)), and runs in O(1) on maps without deadlines.
+func (m *Map[K, V]) CountKeys(value V) int {
+	if traceEnabled {
+		defer traceStart("CountKeys")()
+	}
+	m.requireReverse("CountKeys")
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	return fmt.Sprintf("Map[%d]{%v}", len(m.data), m.data)
+	return m.liveCountLocked(m.reverseMap[value])
 }
 
-// removeFromReverseMap removes a key from the reverse map for a given value.
-// This is an internal method and assumes the caller holds the appropriate lock.
-func (m *Map[K, V]) removeFromReverseMap(key K, value V) {
-	if keyMap, exists := m.reverseMap[value]; exists {
-		delete(keyMap, key)
-		if len(keyMap) == 0 {
+// CountKeysMany returns, for each of the given values, the number of keys
+// associated with it; absent values map to 0. All counts are read under a
+// single read lock, so they reflect the same state of the map.
+func (m *Map[K, V]) CountKeysMany(values ...V) map[V]int {
+	if traceEnabled {
+		defer traceStart("CountKeysMany")()
+	}
+	m.requireReverse("CountKeysMany")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	counts := make(map[V]int, len(values))
+	for _, v := range values {
+		counts[v] = m.liveCountLocked(m.reverseMap[v])
+	}
+	return counts
+}
+
+// List returns all keys in the map.
+func (m *Map[K, V]) List() []K {
+	if traceEnabled {
+		defer traceStart("List")()
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := make([]K, len(m.data))
+	i := 0
+	for k := range m.data {
+		keys[i] = k
+		i++
+	}
+	m.orderKeys(keys)
+	return keys
+}
+
+// ListPooled returns all keys in the map like List, but draws the slice
+// from a pool owned by the map, so callers polling in a tight loop can
+// recycle buffers instead of allocating a new one every time.
+//
+// The slice is a snapshot taken at call time. The caller must call release
+// once done with it and must not use the slice, or any slice sharing its
+// memory, afterwards. Calling release more than once has no further effect.
+//
+// Example:
+//
+//	keys, release := m.ListPooled()
+//	defer release()
+func (m *Map[K, V]) ListPooled() ([]K, func()) {
+	if traceEnabled {
+		defer traceStart("ListPooled")()
+	}
+	buf, _ := m.keyPool.Get().(*[]K)
+	if buf == nil {
+		buf = new([]K)
+	}
+
+	m.mu.RLock()
+	keys := (*buf)[:0]
+	for k := range m.data {
+		keys = append(keys, k)
+	}
+	m.mu.RUnlock()
+	m.orderKeys(keys)
+
+	released := false
+	return keys, func() {
+		if released {
+			return
+		}
+		released = true
+		clear(keys) // drop references held by the buffer while it is pooled
+		*buf = keys[:0]
+		m.keyPool.Put(buf)
+	}
+}
+
+// Values returns all values in the map, one per key, so a value shared by
+// several keys appears several times. See DistinctValues for unique values.
+func (m *Map[K, V]) Values() []V {
+	if traceEnabled {
+		defer traceStart("Values")()
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	values := make([]V, len(m.data))
+	i := 0
+	for _, v := range m.data {
+		values[i] = v
+		i++
+	}
+	m.orderValues(values)
+	return values
+}
+
+// Entries returns all key-value pairs in the map.
+// The pairs are captured under a single read lock, so each pair is a
+// consistent key/value tuple from the same state of the map. Order is
+// unspecified.
+func (m *Map[K, V]) Entries() []Pair[K, V] {
+	if traceEnabled {
+		defer traceStart("Entries")()
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entries := make([]Pair[K, V], 0, len(m.data))
+	for k, v := range m.data {
+		entries = append(entries, Pair[K, V]{Key: k, Value: v})
+	}
+	return entries
+}
+
+// SortedKeys returns all keys in the map sorted according to less.
+// The keys are snapshotted under the read lock and sorted after it is
+// released, giving a deterministic order for display and testing.
+func (m *Map[K, V]) SortedKeys(less func(a, b K) bool) []K {
+	if traceEnabled {
+		defer traceStart("SortedKeys")()
+	}
+	keys := m.List()
+	sort.Slice(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+	return keys
+}
+
+// SortedValues returns all values in the map, one per key, sorted according
+// to less. Like SortedKeys, it sorts a private snapshot.
+func (m *Map[K, V]) SortedValues(less func(a, b V) bool) []V {
+	if traceEnabled {
+		defer traceStart("SortedValues")()
+	}
+	values := m.Values()
+	sort.Slice(values, func(i, j int) bool { return less(values[i], values[j]) })
+	return values
+}
+
+// DistinctValues returns the set of unique values in the map: each value
+// appears exactly once, no matter how many keys share it. Use Values for
+// the multiset with one entry per key.
+//
+// It runs in O(number of distinct values) rather than O(number of keys) by
+// reading the values directly from the reverse index.
+func (m *Map[K, V]) DistinctValues() []V {
+	if traceEnabled {
+		defer traceStart("DistinctValues")()
+	}
+	m.requireReverse("DistinctValues")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	values := make([]V, 0, len(m.reverseMap))
+	for v, keyMap := range m.reverseMap {
+		if _, ok := m.liveKeyLocked(keyMap); ok {
+			values = append(values, v)
+		}
+	}
+	m.orderValues(values)
+	return values
+}
+
+// ValueCounts returns a histogram of the map: for every distinct value, the
+// number of keys mapped to it. It is read straight from the reverse index in
+// a single pass. The returned map is a fresh copy that callers may modify.
+func (m *Map[K, V]) ValueCounts() map[V]int {
+	if traceEnabled {
+		defer traceStart("ValueCounts")()
+	}
+	m.requireReverse("ValueCounts")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	counts := make(map[V]int, len(m.reverseMap))
+	for v, keyMap := range m.reverseMap {
+		if n := m.liveCountLocked(keyMap); n > 0 {
+			counts[v] = n
+		}
+	}
+	return counts
+}
+
+// ValuesByFrequency returns every distinct value paired with the number of
+// keys mapped to it, most frequent first. It is computed from the reverse
+// index in a single read-locked pass. Values with equal counts keep the
+// order DistinctValues would give them: ascending on maps created with
+// NewSorted, unspecified otherwise.
+func (m *Map[K, V]) ValuesByFrequency() []Pair[V, int] {
+	if traceEnabled {
+		defer traceStart("ValuesByFrequency")()
+	}
+	m.requireReverse("ValuesByFrequency")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	values := make([]V, 0, len(m.reverseMap))
+	for v, keyMap := range m.reverseMap {
+		if _, ok := m.liveKeyLocked(keyMap); ok {
+			values = append(values, v)
+		}
+	}
+	m.orderValues(values)
+
+	ranked := make([]Pair[V, int], len(values))
+	for i, v := range values {
+		ranked[i] = Pair[V, int]{Key: v, Value: m.liveCountLocked(m.reverseMap[v])}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Value > ranked[j].Value })
+	return ranked
+}
+
+// RangeKeys calls fn for each key associated with the value, walking the
+// reverse index directly instead of copying the keys into a slice as
+// GetKeys does. If fn returns false, the iteration stops. Keys are visited
+// in unspecified order, even on maps created with NewSorted.
+//
+// The read lock is held for the duration of the call, so fn must not call
+// any method that mutates the map, or it will deadlock.
+func (m *Map[K, V]) RangeKeys(value V, fn func(key K) bool) {
+	if traceEnabled {
+		defer traceStart("RangeKeys")()
+	}
+	m.requireReverse("RangeKeys")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keyMap := m.reverseMap[value]
+	m.metrics.ObserveReverseFanout(len(keyMap))
+	for key := range keyMap {
+		if m.expiredLocked(key) {
+			continue
+		}
+		if !fn(key) {
+			return
+		}
+	}
+}
+
+// ForEachValue calls fn once for each distinct value in the map, together
+// with a freshly allocated slice of the keys mapping to it. If fn returns
+// false, the iteration stops.
+//
+// The read lock is held for the duration of the call, so fn must not call
+// any method that mutates the map. fn may keep the keys slice.
+func (m *Map[K, V]) ForEachValue(fn func(value V, keys []K) bool) {
+	if traceEnabled {
+		defer traceStart("ForEachValue")()
+	}
+	m.requireReverse("ForEachValue")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for v, keyMap := range m.reverseMap {
+		keys := make([]K, 0, len(keyMap))
+		for k := range keyMap {
+			if !m.expiredLocked(k) {
+				keys = append(keys, k)
+			}
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		if !fn(v, keys) {
+			return
+		}
+	}
+}
+
+// ToMap returns a copy of the map's contents as a native Go map, leaving
+// out expired entries. The returned map is owned by the caller and can be
+// modified freely without affecting the Map.
+func (m *Map[K, V]) ToMap() map[K]V {
+	if traceEnabled {
+		defer traceStart("ToMap")()
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make(map[K]V, len(m.data))
+	for k, v := range m.data {
+		if !m.expiredLocked(k) {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// Range calls fn sequentially for each key-value pair in the map.
+// If fn returns false, Range stops the iteration.
+//
+// The read lock is held for the duration of the call, so fn must not call
+// any method that mutates the map (Set, Remove, Clear, ...), or it will
+// deadlock. Iteration order is unspecified.
+func (m *Map[K, V]) Range(fn func(key K, value V) bool) {
+	if traceEnabled {
+		defer traceStart("Range")()
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for k, v := range m.data {
+		if !fn(k, v) {
+			return
+		}
+	}
+}
+
+// Remove removes a key-value pair from the map.
+// Returns true if the key existed and was removed, false otherwise.
+func (m *Map[K, V]) Remove(key K) bool {
+	if traceEnabled {
+		defer traceStart("Remove")()
+	}
+	key = m.normKey(key)
+	m.lock()
+	defer m.unlock()
+
+	m.expireLocked(key)
+	_, removed := m.deleteLocked(key)
+	return removed
+}
+
+// Pop retrieves and removes the value for the key in a single atomic step.
+// Returns the removed value and true if the key existed; otherwise it
+// returns the zero value and false and leaves the map unchanged.
+func (m *Map[K, V]) Pop(key K) (V, bool) {
+	if traceEnabled {
+		defer traceStart("Pop")()
+	}
+	key = m.normKey(key)
+	m.lock()
+	defer m.unlock()
+
+	m.expireLocked(key)
+	return m.deleteLocked(key)
+}
+
+// PopAny removes an arbitrary entry and returns its key and value. Which
+// entry is chosen is unspecified. Returns false if the map is empty, which
+// makes it suitable for draining the map as a concurrent work set:
+//
+//	for k, v, ok := m.PopAny(); ok; k, v, ok = m.PopAny() {
+//		process(k, v)
+//	}
+func (m *Map[K, V]) PopAny() (K, V, bool) {
+	if traceEnabled {
+		defer traceStart("PopAny")()
+	}
+	m.lock()
+	defer m.unlock()
+
+	for key := range m.data {
+		if m.expiredLocked(key) {
+			m.deleteLocked(key)
+			continue
+		}
+		value, _ := m.deleteLocked(key)
+		return key, value, true
+	}
+	var zeroK K
+	var zeroV V
+	return zeroK, zeroV, false
+}
+
+// CompareAndDelete removes the key only if it exists and its current value
+// equals old. Returns true if the entry was removed; otherwise the map is
+// left unchanged.
+func (m *Map[K, V]) CompareAndDelete(key K, old V) bool {
+	if traceEnabled {
+		defer traceStart("CompareAndDelete")()
+	}
+	key = m.normKey(key)
+	m.lock()
+	defer m.unlock()
+
+	m.expireLocked(key)
+	if current, exists := m.data[key]; !exists || current != old {
+		return false
+	}
+	m.deleteLocked(key)
+	return true
+}
+
+// RemoveValue removes every key that maps to the given value.
+// Returns the removed keys, or an empty slice if no key had the value.
+// Expired keys holding the value are purged as well but not returned.
+//
+// Collecting the keys and removing them happen under a single write lock,
+// so RemoveValue is an atomic get-and-remove ("claim everything for this
+// value"): a concurrent writer cannot add a key to the value between the
+// lookup and the removal, and every key is claimed by exactly one caller.
+func (m *Map[K, V]) RemoveValue(value V) []K {
+	if traceEnabled {
+		defer traceStart("RemoveValue")()
+	}
+	m.requireReverse("RemoveValue")
+	m.lock()
+	defer m.unlock()
+
+	keyMap, ok := m.reverseMap[value]
+	if !ok {
+		return []K{}
+	}
+	keys := make([]K, 0, len(keyMap))
+	for key := range keyMap {
+		if m.expiredLocked(key) {
+			m.deleteLocked(key)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	for _, key := range keys {
+		m.deleteLocked(key)
+	}
+	return keys
+}
+
+// RemoveIf removes every entry for which pred returns true and returns the
+// number of entries removed. The whole scan runs under a single write lock,
+// so pred must not call any method on the map.
+func (m *Map[K, V]) RemoveIf(pred func(key K, value V) bool) int {
+	if traceEnabled {
+		defer traceStart("RemoveIf")()
+	}
+	m.lock()
+	defer m.unlock()
+
+	removed := 0
+	for k, v := range m.data {
+		// Deleting the current entry during range is safe in Go
+		if pred(k, v) {
+			m.deleteLocked(k)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Clear removes all key-value pairs from the map.
+// The underlying storage is cleared in place so the map keeps its allocated
+// capacity and can be refilled without regrowing.
+func (m *Map[K, V]) Clear() {
+	if traceEnabled {
+		defer traceStart("Clear")()
+	}
+	m.lock()
+	defer m.unlock()
+
+	m.clearLocked()
+}
+
+// Drain removes every entry and returns them as a native map, in a single
+// atomic step: each entry is handed to exactly one caller, and no write can
+// slip in between the copy and the reset. Unlike ToMap the map ends up
+// empty, and unlike Clear the entries are returned rather than discarded.
+// Entries that have already expired are dropped without being returned.
+func (m *Map[K, V]) Drain() map[K]V {
+	if traceEnabled {
+		defer traceStart("Drain")()
+	}
+	m.lock()
+	defer m.unlock()
+
+	result := make(map[K]V, len(m.data))
+	for k, v := range m.data {
+		if !m.expiredLocked(k) {
+			result[k] = v
+		}
+	}
+	m.clearLocked()
+	return result
+}
+
+// ReplaceAll atomically replaces the whole contents of the map with data.
+// Every prior entry not present in data is gone afterwards, and the reverse
+// index is rebuilt for the new entries. The swap happens under a single
+// write lock, so readers see either the old contents or the new ones, never
+// an empty or partially loaded map.
+func (m *Map[K, V]) ReplaceAll(data map[K]V) {
+	if traceEnabled {
+		defer traceStart("ReplaceAll")()
+	}
+	m.lock()
+	defer m.unlock()
+
+	m.clearLocked()
+	for k, v := range data {
+		m.setLocked(m.normKey(k), v)
+	}
+}
+
+// Shrink releases memory held by the map after it has shrunk from a much
+// larger size. Go maps never give back their buckets, so Shrink allocates
+// fresh storage sized to the current length, copies the entries over and
+// drops the oversized maps so they can be garbage collected.
+func (m *Map[K, V]) Shrink() {
+	if traceEnabled {
+		defer traceStart("Shrink")()
+	}
+	m.lock()
+	defer m.unlock()
+
+	data := make(map[K]V, len(m.data))
+	for k, v := range m.data {
+		data[k] = v
+	}
+	reverseMap := make(map[V]map[K]struct{}, len(m.reverseMap))
+	for v, keyMap := range m.reverseMap {
+		reverseMap[v] = keyMap
+	}
+	m.data = data
+	m.reverseMap = reverseMap
+	m.peak = len(data)
+}
+
+// Compact is a deeper Shrink for long-running maps with cyclic load. Like
+// Shrink it reallocates the forward data and the reverse index at their
+// current size, but it also rebuilds every per-value key set, whose storage
+// stays grown after keys are removed from it, and drops any empty set.
+// It runs in O(n) under the write lock.
+func (m *Map[K, V]) Compact() {
+	if traceEnabled {
+		defer traceStart("Compact")()
+	}
+	m.lock()
+	defer m.unlock()
+
+	data := make(map[K]V, len(m.data))
+	for k, v := range m.data {
+		data[k] = v
+	}
+	reverseMap := make(map[V]map[K]struct{}, len(m.reverseMap))
+	for v, keyMap := range m.reverseMap {
+		if len(keyMap) == 0 {
+			continue
+		}
+		compacted := make(map[K]struct{}, len(keyMap))
+		for k := range keyMap {
+			compacted[k] = struct{}{}
+		}
+		reverseMap[v] = compacted
+	}
+	m.data = data
+	m.reverseMap = reverseMap
+	m.peak = len(data)
+}
+
+// Grow makes room for at least n more entries, so that a following burst of
+// inserts does not pay for incremental rehashing. Go maps cannot be grown in
+// place, so when Cap reports too little room Grow allocates fresh storage
+// sized for Len()+n and copies the entries over. This is best-effort: Cap is
+// only an estimate of the real capacity. Grow is a no-op if n <= 0.
+func (m *Map[K, V]) Grow(n int) {
+	if traceEnabled {
+		defer traceStart("Grow")()
+	}
+	m.lock()
+	defer m.unlock()
+
+	if n <= 0 || len(m.data)+n <= m.peak {
+		return
+	}
+	data := make(map[K]V, len(m.data)+n)
+	for k, v := range m.data {
+		data[k] = v
+	}
+	reverseMap := make(map[V]map[K]struct{}, len(m.reverseMap)+n)
+	for v, keyMap := range m.reverseMap {
+		reverseMap[v] = keyMap
+	}
+	m.data = data
+	m.reverseMap = reverseMap
+	m.peak = len(data) + n
+}
+
+// Version returns a counter that grows every time the map's contents
+// change, for cheap change detection: a reader that remembers the version
+// it last saw can skip re-reading the map while it stays the same. The
+// counter is bumped under the write lock together with the change it
+// describes, and read without taking any lock.
+//
+// Only actual changes count; setting a key to the value it already holds
+// or removing a missing key leaves the version as is. Resizing methods
+// such as Shrink and Grow do not change it either. Expired entries count
+// as a change once they are purged. WithLock cannot tell what its callback
+// did, so every call counts as a change.
+func (m *Map[K, V]) Version() uint64 {
+	if traceEnabled {
+		defer traceStart("Version")()
+	}
+	return m.version.Load()
+}
+
+// Cap returns a best-effort estimate of the number of entries the map's
+// storage can hold without growing. Go does not expose the real capacity of
+// a map, so this is the larger of the capacity hint and the peak size
+// reached since the storage was last allocated. Comparing Cap with Len
+// tells whether calling Shrink is worthwhile.
+func (m *Map[K, V]) Cap() int {
+	if traceEnabled {
+		defer traceStart("Cap")()
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.peak
+}
+
+// Len returns the number of key-value pairs in the map.
+func (m *Map[K, V]) Len() int {
+	if traceEnabled {
+		defer traceStart("Len")()
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.data)
+}
+
+// IsEmpty reports whether the map contains no entries.
+func (m *Map[K, V]) IsEmpty() bool {
+	if traceEnabled {
+		defer traceStart("IsEmpty")()
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.data) == 0
+}
+
+// LenValues returns the number of distinct values in the map, not counting
+// values held only by expired keys. It runs in O(1) using the reverse
+// index on maps without deadlines.
+func (m *Map[K, V]) LenValues() int {
+	if traceEnabled {
+		defer traceStart("LenValues")()
+	}
+	m.requireReverse("LenValues")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.expires == nil {
+		return len(m.reverseMap)
+	}
+	n := 0
+	for _, keyMap := range m.reverseMap {
+		if _, ok := m.liveKeyLocked(keyMap); ok {
+			n++
+		}
+	}
+	return n
+}
+
+// Sizes returns the number of keys and the number of distinct values in the
+// reverse index, read under a single read lock so the two are consistent.
+// It is meant for diagnosing index leaks: distinctValues can never exceed
+// keys in a healthy map. On a map created with NewForwardOnly,
+// distinctValues is always 0.
+func (m *Map[K, V]) Sizes() (keys int, distinctValues int) {
+	if traceEnabled {
+		defer traceStart("Sizes")()
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.data), len(m.reverseMap)
+}
+
+// Filter returns a new map containing only the pairs for which pred returns
+// true. Expired entries are left out, and pred is not called for them. The
+// source map is read-locked while filtering and is left unchanged. The read
+// lock is held while pred runs, so pred must not mutate the map.
+func (m *Map[K, V]) Filter(pred func(key K, value V) bool) *Map[K, V] {
+	if traceEnabled {
+		defer traceStart("Filter")()
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := New[K, V]()
+	for k, v := range m.data {
+		if !m.expiredLocked(k) && pred(k, v) {
+			result.setLocked(k, v)
+		}
+	}
+	return result
+}
+
+// SubMap returns a new map holding only the entries for the given keys
+// that are present in m; missing keys are skipped. The result is
+// independent of m and has its own reverse index. All keys are looked up
+// under a single read lock, so the result is a consistent snapshot.
+func (m *Map[K, V]) SubMap(keys ...K) *Map[K, V] {
+	if traceEnabled {
+		defer traceStart("SubMap")()
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := NewWithCapacity[K, V](len(keys))
+	for _, key := range keys {
+		key = m.normKey(key)
+		if v, ok := m.data[key]; ok && !m.expiredLocked(key) {
+			result.setLocked(key, v)
+		}
+	}
+	return result
+}
+
+// Equal reports whether both maps contain exactly the same key-value pairs.
+// Expired entries are ignored on both sides. Both maps are read-locked for
+// the duration of the comparison.
+func (m *Map[K, V]) Equal(other *Map[K, V]) bool {
+	if traceEnabled {
+		defer traceStart("Equal")()
+	}
+	if other == nil {
+		return false
+	}
+	if other == m {
+		return true
+	}
+	unlock := lockTwo(m, other, false)
+	defer unlock()
+
+	if m.liveLenLocked() != other.liveLenLocked() {
+		return false
+	}
+	for k, v := range m.data {
+		if m.expiredLocked(k) {
+			continue
+		}
+		if ov, ok := other.data[k]; !ok || ov != v || other.expiredLocked(k) {
+			return false
+		}
+	}
+	return true
+}
+
+// Diff compares the map with other, from the receiver's point of view:
+// added holds keys present only in other, removed holds keys present only in
+// the receiver, and changed holds keys present in both with different
+// values. Expired entries count as absent on both sides. Both maps are
+// read-locked for the duration of the comparison. The order of each slice
+// is unspecified.
+func (m *Map[K, V]) Diff(other *Map[K, V]) (added, removed, changed []K) {
+	if traceEnabled {
+		defer traceStart("Diff")()
+	}
+	if other == m {
+		return []K{}, []K{}, []K{}
+	}
+	if other == nil {
+		return []K{}, m.List(), []K{}
+	}
+	unlock := lockTwo(m, other, false)
+	defer unlock()
+
+	added, removed, changed = []K{}, []K{}, []K{}
+	for k, v := range m.data {
+		if m.expiredLocked(k) {
+			continue
+		}
+		if ov, ok := other.data[k]; !ok || other.expiredLocked(k) {
+			removed = append(removed, k)
+		} else if ov != v {
+			changed = append(changed, k)
+		}
+	}
+	for k := range other.data {
+		if other.expiredLocked(k) {
+			continue
+		}
+		if _, ok := m.data[k]; !ok || m.expiredLocked(k) {
+			added = append(added, k)
+		}
+	}
+	return added, removed, changed
+}
+
+// IntersectKeys returns the keys present in both m and other. UnionKeys
+// returns the keys present in either, each exactly once. Both read-lock
+// the two maps together, in a consistent order, so the result reflects a
+// single state of each map. Expired keys are treated as absent. The order
+// of the returned keys is unspecified, except on maps created with
+// NewSorted where it is ascending.
+func (m *Map[K, V]) IntersectKeys(other *Map[K, V]) []K {
+	if traceEnabled {
+		defer traceStart("IntersectKeys")()
+	}
+	if other == nil {
+		return []K{}
+	}
+	if other == m {
+		return m.List()
+	}
+	unlock := lockTwo(m, other, false)
+	defer unlock()
+
+	small, large := m, other
+	if len(large.data) < len(small.data) {
+		small, large = large, small
+	}
+	result := []K{}
+	for k := range small.data {
+		if _, ok := large.data[k]; ok && !small.expiredLocked(k) && !large.expiredLocked(k) {
+			result = append(result, k)
+		}
+	}
+	m.orderKeys(result)
+	return result
+}
+
+// UnionKeys returns the keys present in m, other or both, each exactly
+// once. See IntersectKeys for locking and ordering.
+func (m *Map[K, V]) UnionKeys(other *Map[K, V]) []K {
+	if traceEnabled {
+		defer traceStart("UnionKeys")()
+	}
+	if other == nil || other == m {
+		return m.List()
+	}
+	unlock := lockTwo(m, other, false)
+	defer unlock()
+
+	result := make([]K, 0, max(len(m.data), len(other.data)))
+	for k := range m.data {
+		if !m.expiredLocked(k) {
+			result = append(result, k)
+		}
+	}
+	for k := range other.data {
+		if other.expiredLocked(k) {
+			continue
+		}
+		if _, ok := m.data[k]; !ok || m.expiredLocked(k) {
+			result = append(result, k)
+		}
+	}
+	m.orderKeys(result)
+	return result
+}
+
+// String returns a string representation of the map.
+func (m *Map[K, V]) String() string {
+	if traceEnabled {
+		defer traceStart("String")()
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return fmt.Sprintf("Map[%d]{%v}", len(m.data), m.data)
+}
+
+// setLocked adds or updates a key-value pair and keeps the reverse index in sync.
+// It returns false, leaving the map unchanged, if the key is new and a
+// bounded map is full, or if a FanoutReject map refuses the value; see
+// NewBounded and NewWithMaxKeysPerValue.
+// This is an internal method and assumes the caller holds the write lock.
+func (m *Map[K, V]) setLocked(key K, value V) bool {
+	// Single lookup to check existing value
+	oldValue, exists := m.data[key]
+	if !exists && m.bound > 0 && len(m.data) >= m.bound {
+		return false
+	}
+	if m.fanoutLimit > 0 && (!exists || oldValue != value) && !m.fanoutRoomLocked(value) {
+		return false
+	}
+
+	m.metrics.IncSet()
+	if m.ttl > 0 || m.expires != nil {
+		m.setDeadlineLocked(key, m.ttl)
+	}
+	if exists && m.maxSize > 0 {
+		m.touchLocked(key)
+	}
+	if exists && oldValue == value {
+		return true // No-op if key already has this value
+	}
+
+	// Remove key from old value's reverse map if key exists
+	if exists {
+		m.removeFromReverseMap(key, oldValue)
+	} else {
+		if m.maxSize > 0 && len(m.data) >= m.maxSize {
+			m.evictLocked()
+		}
+		if m.order != nil {
+			m.elems[key] = m.order.PushBack(key)
+		}
+		if m.compare != nil {
+			m.insertSortedLocked(key)
+		}
+	}
+
+	// Add to data and reverse maps
+	m.data[key] = value
+	m.version.Add(1)
+	if len(m.data) > m.peak {
+		m.peak = len(m.data)
+	}
+	if !m.forward {
+		if m.reverseMap[value] == nil {
+			m.reverseMap[value] = make(map[K]struct{}, m.keysPerValue)
+		}
+		m.reverseMap[value][key] = struct{}{}
+	}
+	if m.fanoutOrder != nil {
+		m.trackFanoutLocked(key, value)
+	}
+
+	if len(m.hooks) > 0 {
+		m.record(ChangeEvent[K, V]{Op: OpSet, Key: key, OldValue: oldValue, NewValue: value, Existed: exists})
+	}
+	return true
+}
+
+// clearLocked removes all entries in place, keeping the allocated storage.
+// This is an internal method and assumes the caller holds the write lock.
+func (m *Map[K, V]) clearLocked() {
+	if len(m.data) > 0 {
+		m.version.Add(1)
+	}
+	for k, v := range m.data {
+		m.metrics.IncRemove()
+		if len(m.hooks) > 0 {
+			m.record(ChangeEvent[K, V]{Op: OpRemove, Key: k, OldValue: v, Existed: true})
+		}
+	}
+
+	// The compiler turns these loops into a single map clear operation
+	for k := range m.data {
+		delete(m.data, k)
+	}
+	for v := range m.reverseMap {
+		delete(m.reverseMap, v)
+	}
+	for k := range m.expires {
+		delete(m.expires, k)
+	}
+	if m.order != nil {
+		m.order.Init()
+		for k := range m.elems {
+			delete(m.elems, k)
+		}
+	}
+	clear(m.sortedKeys)
+	m.sortedKeys = m.sortedKeys[:0]
+	clear(m.fanoutOrder)
+	clear(m.fanoutElems)
+}
+
+// deleteLocked removes the key from the map and the reverse index.
+// Returns the removed value and whether the key existed.
+// This is an internal method and assumes the caller holds the write lock.
+func (m *Map[K, V]) deleteLocked(key K) (V, bool) {
+	value, exists := m.data[key]
+	if !exists {
+		return value, false
+	}
+	delete(m.data, key)
+	m.removeFromReverseMap(key, value)
+	m.metrics.IncRemove()
+	m.version.Add(1)
+	if m.expires != nil {
+		delete(m.expires, key)
+	}
+	if m.order != nil {
+		m.order.Remove(m.elems[key])
+		delete(m.elems, key)
+	}
+	if m.compare != nil {
+		m.removeSortedLocked(key)
+	}
+	if len(m.hooks) > 0 {
+		m.record(ChangeEvent[K, V]{Op: OpRemove, Key: key, OldValue: value, Existed: true})
+	}
+	return value, true
+}
+
+// removeFromReverseMap removes a key from the reverse map for a given value.
+// This is an internal method and assumes the caller holds the appropriate lock.
+func (m *Map[K, V]) removeFromReverseMap(key K, value V) {
+	if m.forward {
+		return
+	}
+	if keyMap, exists := m.reverseMap[value]; exists {
+		delete(keyMap, key)
+		if len(keyMap) == 0 {
 			delete(m.reverseMap, value)
 		}
 	}
+	if m.fanoutOrder != nil {
+		m.untrackFanoutLocked(key, value)
+	}
+}
+
+// Invert returns a new map with the keys and values of m swapped.
+// It requires a one-to-one mapping: if two keys share a value the inverse
+// would be ambiguous, and an error wrapping ErrDuplicateValue that names the
+// conflicting value is returned instead. Expired entries are left out and do
+// not count towards a conflict.
+func Invert[K comparable, V comparable](m *Map[K, V]) (*Map[V, K], error) {
+	m.requireReverse("Invert")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := NewWithCapacity[V, K](len(m.data))
+	for v, keyMap := range m.reverseMap {
+		if n := m.liveCountLocked(keyMap); n > 1 {
+			return nil, fmt.Errorf("%w: %v is mapped by %d keys", ErrDuplicateValue, v, n)
+		}
+		for k := range keyMap {
+			if !m.expiredLocked(k) {
+				result.setLocked(v, k)
+			}
+		}
+	}
+	return result, nil
+}
+
+// TransformValues returns a new map with the same keys as m and each value
+// replaced by fn(value). The result has its own reverse index over the
+// transformed values, so keys whose values collapse to the same V2 share
+// one entry in it. Expired entries are left out, and fn is not called for
+// them. The source map is read-locked while transforming and is left
+// unchanged; fn must not mutate it. It is a free function because methods
+// cannot introduce the new type parameter V2.
+func TransformValues[K comparable, V comparable, V2 comparable](m *Map[K, V], fn func(V) V2) *Map[K, V2] {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := NewWithCapacity[K, V2](len(m.data))
+	for k, v := range m.data {
+		if !m.expiredLocked(k) {
+			result.setLocked(k, fn(v))
+		}
+	}
+	return result
+}
+
+// Increment atomically adds delta to the value stored for key, treating a
+// missing key as zero, and returns the new value. If the map refuses the
+// write, as described for Offer, the value is left as is and Increment
+// returns it unchanged, which is zero for a missing key.
+//
+// The reverse index is kept up to date like for any other write: each
+// increment moves the key from the old value's key set to the new one,
+// which is O(1) but allocates when the new value has no keys yet. For
+// counters that are never looked up by value this is overhead worth
+// measuring.
+func Increment[K comparable](m *Map[K, int64], key K, delta int64) int64 {
+	key = m.normKey(key)
+	m.lock()
+	defer m.unlock()
+
+	m.expireLocked(key)
+	old := m.data[key]
+	if !m.setLocked(key, old+delta) {
+		return old
+	}
+	return old + delta
+}
+
+// lockTwo locks two distinct maps for a cross-map operation. b is always
+// read-locked; a is write-locked when write is true and read-locked
+// otherwise. Locks are always acquired in ascending id order, whatever the
+// argument order, so concurrent calls such as a.Merge(b) and b.Merge(a)
+// cannot deadlock. Every method that locks two maps must go through
+// lockTwo.
+//
+// It returns a function that releases both locks. When a is write-locked,
+// its change events are delivered after both locks have been released. If a
+// is to be written but is frozen, both locks are released before panicking.
+func lockTwo[K comparable, V comparable](a, b *Map[K, V], write bool) func() {
+	lockA, unlockA := a.mu.RLock, a.mu.RUnlock
+	if write {
+		lockA, unlockA = a.mu.Lock, a.unlock
+	}
+	if a.id < b.id {
+		lockA()
+		b.mu.RLock()
+	} else {
+		b.mu.RLock()
+		lockA()
+	}
+	if write && a.frozen.Load() {
+		b.mu.RUnlock()
+		a.checkFrozenLocked()
+	}
+	return func() {
+		b.mu.RUnlock()
+		unlockA()
+	}
 }
@@ -1,8 +1,10 @@
 package genericmap
 
 import (
+	"errors"
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
 	"testing"
 )
@@ -26,6 +28,150 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNewInitialDataMerge(t *testing.T) {
+	// No initial data
+	if m := New[string, int](); m.Len() != 0 {
+		t.Errorf("Expected empty map, got length %d", m.Len())
+	}
+
+	// Nil initial data is skipped
+	if m := New[string, int](nil); m.Len() != 0 {
+		t.Errorf("Expected empty map for nil data, got length %d", m.Len())
+	}
+
+	// Overlapping keys take the value from the last map
+	m1 := map[string]int{"a": 1, "b": 2}
+	m2 := map[string]int{"b": 3, "c": 4}
+	m := New(m1, nil, m2)
+	if m.Len() != 3 {
+		t.Errorf("Expected 3 items, got %d", m.Len())
+	}
+	if val, _ := m.Get("b"); val != 3 {
+		t.Errorf("Expected b=3 from the last map, got %v", val)
+	}
+	if m.ContainsValue(2) {
+		t.Errorf("Expected overridden value 2 to be absent from reverse index")
+	}
+}
+
+func TestNewStrict(t *testing.T) {
+	// Overlapping keys with equal values are accepted
+	m, err := NewStrict(map[string]int{"a": 1, "b": 2}, nil, map[string]int{"b": 2, "c": 3})
+	if err != nil {
+		t.Fatalf("NewStrict failed: %v", err)
+	}
+	if m.Len() != 3 || m.CountKeys(2) != 1 {
+		t.Errorf("Expected 3 items with b=2, got %v", m)
+	}
+
+	m, err = NewStrict(map[string]int{"a": 1, "b": 2}, map[string]int{"b": 3})
+	if !errors.Is(err, ErrKeyConflict) {
+		t.Fatalf("Expected ErrKeyConflict, got %v", err)
+	}
+	if m != nil {
+		t.Errorf("Expected nil map on error, got %v", m)
+	}
+	if !strings.Contains(err.Error(), "b is set to both 2 and 3") {
+		t.Errorf("Expected error to name the key and both values, got %q", err)
+	}
+}
+
+func TestNewWithCapacityAndData(t *testing.T) {
+	m := NewWithCapacityAndData[string, int](100, map[string]int{"a": 1, "b": 2, "c": 1})
+
+	if m.Len() != 3 {
+		t.Errorf("Expected 3 items, got %d", m.Len())
+	}
+	if m.Cap() != 100 {
+		t.Errorf("Expected Cap 100, got %d", m.Cap())
+	}
+	if n := m.CountKeys(1); n != 2 {
+		t.Errorf("Expected 2 keys for value 1, got %d", n)
+	}
+
+	// Capacity smaller than the data grows to fit it
+	m = NewWithCapacityAndData[string, int](1, map[string]int{"a": 1, "b": 2})
+	if m.Cap() != 2 {
+		t.Errorf("Expected Cap 2, got %d", m.Cap())
+	}
+
+	m = NewWithCapacityAndData[string, int](10, nil)
+	if m.Len() != 0 {
+		t.Errorf("Expected empty map for nil data, got length %d", m.Len())
+	}
+}
+
+func TestNewWithReverseHint(t *testing.T) {
+	m := NewWithReverseHint[int, string](500)
+	for i := 0; i < 1000; i++ {
+		m.Set(i, fmt.Sprintf("bucket-%d", i%2))
+	}
+
+	if n := m.CountKeys("bucket-0"); n != 500 {
+		t.Errorf("Expected 500 keys for bucket-0, got %d", n)
+	}
+	if err := m.CheckIntegrity(); err != nil {
+		t.Errorf("Expected consistent map, got %v", err)
+	}
+	if m := NewWithReverseHint[int, string](-1); m.keysPerValue != 0 {
+		t.Errorf("Expected negative hint to be ignored, got %d", m.keysPerValue)
+	}
+}
+
+func TestNewUnique(t *testing.T) {
+	m, err := NewUnique(map[string]int{"one": 1, "two": 2})
+	if err != nil {
+		t.Fatalf("NewUnique failed: %v", err)
+	}
+	if m.Len() != 2 || m.CountKeys(1) != 1 {
+		t.Errorf("Expected one key per value, got %v", m)
+	}
+
+	m, err = NewUnique(map[string]int{"one": 1, "uno": 1, "two": 2})
+	if !errors.Is(err, ErrDuplicateValue) {
+		t.Fatalf("Expected ErrDuplicateValue, got %v", err)
+	}
+	if m != nil {
+		t.Errorf("Expected nil map on error, got %v", m)
+	}
+	if !strings.Contains(err.Error(), "1 is mapped by 2 keys") {
+		t.Errorf("Expected error to name the shared value, got %q", err)
+	}
+
+	if m, err := NewUnique[string, int](nil); err != nil || m.Len() != 0 {
+		t.Errorf("Expected empty map for nil data, got %v, %v", m, err)
+	}
+}
+
+func TestNewFromMap(t *testing.T) {
+	src := New[string, int](map[string]int{"a": 1, "b": 2, "c": 1})
+
+	backup := NewFromMap(src)
+	if !backup.Equal(src) {
+		t.Errorf("Expected copy to equal source, got %v", backup)
+	}
+	if n := backup.CountKeys(1); n != 2 {
+		t.Errorf("Expected 2 keys for value 1 in copy, got %d", n)
+	}
+
+	// The copy is independent of the source
+	src.Set("a", 5)
+	backup.Remove("b")
+	if val, _ := backup.Get("a"); val != 1 {
+		t.Errorf("Expected copy to keep a=1, got %v", val)
+	}
+	if !src.Contains("b") {
+		t.Errorf("Expected source to keep b")
+	}
+
+	// Expired entries are not carried into the copy
+	expireNow(src, "c")
+	backup = NewFromMap(src)
+	if backup.Contains("c") || backup.Len() != 2 {
+		t.Errorf("Expected copy to leave out expired key c, got %v", backup)
+	}
+}
+
 func TestSetAndGet(t *testing.T) {
 	m := New[string, int]()
 
@@ -47,6 +193,932 @@ func TestSetAndGet(t *testing.T) {
 	}
 }
 
+func TestSetMany(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 2})
+
+	m.SetMany(map[string]int{"a": 3, "c": 3, "d": 4})
+
+	if m.Len() != 4 {
+		t.Errorf("Expected 4 items, got %d", m.Len())
+	}
+	if val, ok := m.Get("a"); !ok || val != 3 {
+		t.Errorf("Expected a=3, got %v, exists: %v", val, ok)
+	}
+	if m.ContainsValue(1) {
+		t.Errorf("Expected value 1 to be dropped from reverse index")
+	}
+	keys := m.GetKeys(3)
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "c" {
+		t.Errorf("Expected keys [a c] for value 3, got %v", keys)
+	}
+
+	// Empty batch is a no-op
+	m.SetMany(nil)
+	if m.Len() != 4 {
+		t.Errorf("Expected 4 items after empty batch, got %d", m.Len())
+	}
+}
+
+func TestSetPairs(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1})
+
+	err := m.SetPairs([]string{"a", "b", "c", "b"}, []int{2, 2, 3, 4})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if m.Len() != 3 {
+		t.Errorf("Expected 3 items, got %d", m.Len())
+	}
+	if val, _ := m.Get("b"); val != 4 {
+		t.Errorf("Expected last occurrence b=4 to win, got %d", val)
+	}
+	if m.ContainsValue(1) {
+		t.Errorf("Expected value 1 to be dropped from reverse index")
+	}
+	if keys := m.GetKeys(2); len(keys) != 1 || keys[0] != "a" {
+		t.Errorf("Expected keys [a] for value 2, got %v", keys)
+	}
+
+	err = m.SetPairs([]string{"x", "y"}, []int{9})
+	if !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("Expected ErrLengthMismatch, got %v", err)
+	}
+	if m.Contains("x") {
+		t.Errorf("Expected map unchanged after length mismatch")
+	}
+}
+
+func TestSetAll(t *testing.T) {
+	src := New[string, int](map[string]int{"a": 1, "b": 2, "c": 3})
+
+	// Copy the entries with even values into another map
+	var even []Pair[string, int]
+	for _, p := range src.Entries() {
+		if p.Value%2 == 0 {
+			even = append(even, p)
+		}
+	}
+	dst := New[string, int](map[string]int{"z": 2})
+	dst.SetAll(even)
+	if dst.Len() != 2 || dst.CountKeys(2) != 2 {
+		t.Errorf("Expected b and z to map to 2, got %v", dst.ToMap())
+	}
+
+	// Duplicate keys: last one wins
+	dst.SetAll([]Pair[string, int]{{Key: "k", Value: 1}, {Key: "k", Value: 5}})
+	if val, _ := dst.Get("k"); val != 5 || dst.ContainsValue(1) {
+		t.Errorf("Expected last pair k=5 to win, got %d", val)
+	}
+}
+
+func TestSetValidated(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1})
+	errNegative := errors.New("negative")
+
+	inserted, failures := m.SetValidated(map[string]int{"a": 2, "b": -1, "c": 3, "d": -4}, func(_ string, v int) error {
+		if v < 0 {
+			return errNegative
+		}
+		return nil
+	})
+	if inserted != 2 {
+		t.Errorf("Expected 2 inserted, got %d", inserted)
+	}
+	if len(failures) != 2 || failures["b"] != errNegative || failures["d"] != errNegative {
+		t.Errorf("Expected failures for b and d, got %v", failures)
+	}
+	if m.Contains("b") || m.Contains("d") {
+		t.Errorf("Expected rejected entries to be skipped")
+	}
+	if val, _ := m.Get("a"); val != 2 {
+		t.Errorf("Expected a=2, got %d", val)
+	}
+	if m.ContainsValue(1) {
+		t.Errorf("Expected value 1 to be dropped from reverse index")
+	}
+
+	inserted, failures = m.SetValidated(nil, func(string, int) error { return nil })
+	if inserted != 0 || failures == nil || len(failures) != 0 {
+		t.Errorf("Expected (0, empty map) for empty batch, got (%d, %v)", inserted, failures)
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 2, "c": 3})
+
+	applied := m.ApplyPatch(
+		map[string]int{"a": 1, "b": 5, "c": 7, "d": 4},
+		[]string{"c", "missing"},
+	)
+	// b changed, c removed then re-added, d inserted; a was already 1
+	if applied != 4 {
+		t.Errorf("Expected 4 mutations, got %d", applied)
+	}
+	expected := map[string]int{"a": 1, "b": 5, "c": 7, "d": 4}
+	for k, v := range expected {
+		if val, ok := m.Get(k); !ok || val != v {
+			t.Errorf("Expected %s=%d, got %v, exists: %v", k, v, val, ok)
+		}
+	}
+	if m.ContainsValue(2) || m.ContainsValue(3) {
+		t.Errorf("Expected old values to be dropped from reverse index")
+	}
+
+	if applied := m.ApplyPatch(nil, []string{"a"}); applied != 1 || m.Contains("a") {
+		t.Errorf("Expected removal-only patch to apply 1 mutation, got %d", applied)
+	}
+	if applied := m.ApplyPatch(nil, nil); applied != 0 {
+		t.Errorf("Expected empty patch to apply nothing, got %d", applied)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 2})
+	other := New[string, int](map[string]int{"b": 3, "c": 3})
+
+	m.Merge(other)
+
+	if m.Len() != 3 {
+		t.Errorf("Expected 3 items after merge, got %d", m.Len())
+	}
+	if val, _ := m.Get("a"); val != 1 {
+		t.Errorf("Expected receiver-only key a=1, got %v", val)
+	}
+	if val, _ := m.Get("b"); val != 3 {
+		t.Errorf("Expected conflicting key b to take other's value 3, got %v", val)
+	}
+	if m.ContainsValue(2) {
+		t.Errorf("Expected value 2 to be dropped from reverse index")
+	}
+	if n := m.CountKeys(3); n != 2 {
+		t.Errorf("Expected 2 keys for value 3, got %d", n)
+	}
+	if other.Len() != 2 {
+		t.Errorf("Expected other to be unchanged, got length %d", other.Len())
+	}
+
+	// Merging nil or itself is a no-op
+	m.Merge(nil)
+	m.Merge(m)
+	if m.Len() != 3 {
+		t.Errorf("Expected 3 items after no-op merges, got %d", m.Len())
+	}
+}
+
+func TestMergeConcurrent(t *testing.T) {
+	a := New[int, int](map[int]int{1: 1, 2: 2})
+	b := New[int, int](map[int]int{3: 3, 4: 4})
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			a.Merge(b)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			b.Merge(a)
+		}
+	}()
+	wg.Wait()
+
+	if a.Len() != 4 || b.Len() != 4 {
+		t.Errorf("Expected both maps to hold 4 items, got %d and %d", a.Len(), b.Len())
+	}
+}
+
+func TestCopyInto(t *testing.T) {
+	src := New[string, int](map[string]int{"a": 1, "b": 1, "c": 2})
+	dst := New[string, int](map[string]int{"x": 1, "c": 9})
+
+	src.CopyInto(dst)
+
+	if !dst.Equal(src) {
+		t.Errorf("Expected dst to equal src, got %v", dst)
+	}
+	if n := dst.CountKeys(1); n != 2 {
+		t.Errorf("Expected 2 keys for value 1, got %d", n)
+	}
+	if dst.ContainsValue(9) || dst.Contains("x") {
+		t.Errorf("Expected previous contents of dst to be cleared")
+	}
+
+	// The copy is independent of the source
+	src.Set("d", 4)
+	if dst.Contains("d") {
+		t.Errorf("Expected dst not to see later changes to src")
+	}
+
+	// Copying into nil or itself is a no-op
+	src.CopyInto(nil)
+	src.CopyInto(src)
+	if src.Len() != 4 {
+		t.Errorf("Expected 4 items after no-op copies, got %d", src.Len())
+	}
+
+	// Copies in both directions at once must not deadlock
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			src.CopyInto(dst)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			dst.CopyInto(src)
+		}
+	}()
+	wg.Wait()
+}
+
+func TestLockTwoOrdering(t *testing.T) {
+	a := New[int, int](map[int]int{1: 1, 2: 2})
+	b := New[int, int](map[int]int{3: 3, 4: 4})
+
+	// Mix write and read cross-map operations in both argument orders
+	var wg sync.WaitGroup
+	for _, pair := range [][2]*Map[int, int]{{a, b}, {b, a}} {
+		x, y := pair[0], pair[1]
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				x.Merge(y)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				x.Equal(y)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				x.Diff(y)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if !a.Equal(b) {
+		t.Errorf("Expected maps to be equal after cross merges")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	m := New[string, int](map[string]int{"alice": 90, "bob": 60, "carol": 90, "dave": 75})
+
+	high := m.Filter(func(_ string, score int) bool { return score > 70 })
+
+	if high.Len() != 3 {
+		t.Errorf("Expected 3 matching entries, got %d", high.Len())
+	}
+	if high.Contains("bob") {
+		t.Errorf("Expected bob to be filtered out")
+	}
+	keys := high.GetKeys(90)
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "alice" || keys[1] != "carol" {
+		t.Errorf("Expected keys [alice carol] for value 90, got %v", keys)
+	}
+	if m.Len() != 4 {
+		t.Errorf("Expected source to be unchanged, got length %d", m.Len())
+	}
+
+	// Filtered map is independent of the source
+	high.Remove("alice")
+	if !m.Contains("alice") {
+		t.Errorf("Expected source to keep alice after removing it from the filtered map")
+	}
+
+	// Expired entries are not carried into the result
+	expireNow(m, "carol")
+	high = m.Filter(func(string, int) bool { return true })
+	if high.Contains("carol") || high.Len() != 3 {
+		t.Errorf("Expected result to leave out expired key carol, got %v", high)
+	}
+}
+
+func TestSubMap(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 2, "c": 1, "d": 4})
+
+	sub := m.SubMap("a", "c", "missing")
+	if sub.Len() != 2 {
+		t.Errorf("Expected 2 items, got %d", sub.Len())
+	}
+	keys := sub.GetKeys(1)
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "c" {
+		t.Errorf("Expected keys [a c] for value 1, got %v", keys)
+	}
+
+	// The result is independent of the source
+	sub.Set("a", 9)
+	if val, _ := m.Get("a"); val != 1 {
+		t.Errorf("Expected source to be unaffected, got a=%d", val)
+	}
+	if sub := m.SubMap(); sub.Len() != 0 {
+		t.Errorf("Expected empty map for no keys, got length %d", sub.Len())
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a := New[string, int](map[string]int{"a": 1, "b": 2})
+	b := New[string, int](map[string]int{"b": 2, "a": 1})
+
+	if !a.Equal(b) || !b.Equal(a) {
+		t.Errorf("Expected maps with identical pairs to be equal")
+	}
+	if !a.Equal(a) {
+		t.Errorf("Expected map to equal itself")
+	}
+	if a.Equal(nil) {
+		t.Errorf("Expected map not to equal nil")
+	}
+
+	b.Set("b", 3)
+	if a.Equal(b) {
+		t.Errorf("Expected maps with differing values to be unequal")
+	}
+
+	b.Set("b", 2)
+	b.Set("c", 3)
+	if a.Equal(b) {
+		t.Errorf("Expected maps with differing lengths to be unequal")
+	}
+
+	b.Remove("c")
+	b.Remove("a")
+	b.Set("z", 1)
+	if a.Equal(b) {
+		t.Errorf("Expected maps with differing keys to be unequal")
+	}
+
+	// Expired entries count as absent on both sides
+	b = New[string, int](map[string]int{"a": 1, "b": 2, "c": 3})
+	expireNow(b, "c")
+	if !a.Equal(b) || !b.Equal(a) {
+		t.Errorf("Expected an expired extra entry to be ignored")
+	}
+	expireNow(a, "b")
+	if a.Equal(b) || b.Equal(a) {
+		t.Errorf("Expected an expired entry not to match a live one")
+	}
+}
+
+func TestInvert(t *testing.T) {
+	m := New[string, int](map[string]int{"one": 1, "two": 2})
+
+	inv, err := Invert(m)
+	if err != nil {
+		t.Fatalf("Invert failed: %v", err)
+	}
+	if key, ok := inv.Get(1); !ok || key != "one" {
+		t.Errorf("Expected 1 -> one, got %v, exists: %v", key, ok)
+	}
+	if keys := inv.GetKeys("two"); len(keys) != 1 || keys[0] != 2 {
+		t.Errorf("Expected reverse lookup two -> [2], got %v", keys)
+	}
+
+	m.Set("uno", 1)
+	inv, err = Invert(m)
+	if !errors.Is(err, ErrDuplicateValue) {
+		t.Fatalf("Expected ErrDuplicateValue, got %v", err)
+	}
+	if inv != nil {
+		t.Errorf("Expected nil map on error, got %v", inv)
+	}
+	if !strings.Contains(err.Error(), "1 is mapped by 2 keys") {
+		t.Errorf("Expected error to name the conflicting value, got %q", err)
+	}
+
+	// Expired keys neither conflict nor appear in the result
+	expireNow(m, "uno")
+	inv, err = Invert(m)
+	if err != nil {
+		t.Fatalf("Expected expired key not to conflict, got %v", err)
+	}
+	if key, _ := inv.Get(1); key != "one" || inv.Len() != 2 {
+		t.Errorf("Expected 1 -> one and 2 entries, got %v", inv)
+	}
+}
+
+func TestIncrement(t *testing.T) {
+	m := New[string, int64](map[string]int64{"hits": 5})
+
+	if n := Increment(m, "hits", 3); n != 8 {
+		t.Errorf("Expected 8, got %d", n)
+	}
+	if n := Increment(m, "misses", -2); n != -2 {
+		t.Errorf("Expected missing key to start at zero, got %d", n)
+	}
+	if m.ContainsValue(5) || m.CountKeys(8) != 1 {
+		t.Errorf("Expected reverse index to follow the increment")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				Increment(m, "concurrent", 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if val, _ := m.Get("concurrent"); val != 1000 {
+		t.Errorf("Expected 1000 after concurrent increments, got %d", val)
+	}
+	if err := m.CheckIntegrity(); err != nil {
+		t.Errorf("Expected consistent map, got %v", err)
+	}
+}
+
+func TestTransformValues(t *testing.T) {
+	scores := New[string, int](map[string]int{"alice": 92, "bob": 71, "carol": 95})
+
+	grades := TransformValues(scores, func(score int) string {
+		if score >= 90 {
+			return "A"
+		}
+		return "C"
+	})
+
+	if grades.Len() != 3 {
+		t.Errorf("Expected 3 items, got %d", grades.Len())
+	}
+	keys := grades.GetKeys("A")
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "alice" || keys[1] != "carol" {
+		t.Errorf("Expected keys [alice carol] for grade A, got %v", keys)
+	}
+	if val, _ := grades.Get("bob"); val != "C" {
+		t.Errorf("Expected bob=C, got %v", val)
+	}
+	if val, _ := scores.Get("bob"); val != 71 || scores.Len() != 3 {
+		t.Errorf("Expected source map to be unchanged")
+	}
+
+	// Expired entries are not carried into the result
+	expireNow(scores, "bob")
+	grades = TransformValues(scores, func(score int) string { return "X" })
+	if grades.Contains("bob") || grades.Len() != 2 {
+		t.Errorf("Expected result to leave out expired key bob, got %v", grades)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	current := New[string, int](map[string]int{"a": 1, "b": 2, "c": 3})
+	desired := New[string, int](map[string]int{"b": 2, "c": 4, "d": 5})
+
+	added, removed, changed := current.Diff(desired)
+	if fmt.Sprint(added) != "[d]" {
+		t.Errorf("Expected added [d], got %v", added)
+	}
+	if fmt.Sprint(removed) != "[a]" {
+		t.Errorf("Expected removed [a], got %v", removed)
+	}
+	if fmt.Sprint(changed) != "[c]" {
+		t.Errorf("Expected changed [c], got %v", changed)
+	}
+
+	added, removed, changed = current.Diff(current)
+	if len(added)+len(removed)+len(changed) != 0 {
+		t.Errorf("Expected no differences with itself, got %v %v %v", added, removed, changed)
+	}
+
+	added, removed, changed = current.Diff(nil)
+	sort.Strings(removed)
+	if len(added) != 0 || fmt.Sprint(removed) != "[a b c]" || len(changed) != 0 {
+		t.Errorf("Expected every key removed against nil, got %v %v %v", added, removed, changed)
+	}
+
+	// Expired entries count as absent on both sides
+	expireNow(current, "a")
+	expireNow(desired, "c")
+	added, removed, changed = current.Diff(desired)
+	sort.Strings(added)
+	if fmt.Sprint(added) != "[d]" || fmt.Sprint(removed) != "[c]" || len(changed) != 0 {
+		t.Errorf("Expected added [d], removed [c], got %v %v %v", added, removed, changed)
+	}
+}
+
+func TestIntersectAndUnionKeys(t *testing.T) {
+	a := New[string, int](map[string]int{"x": 1, "y": 2, "z": 3})
+	b := New[string, int](map[string]int{"y": 9, "z": 3, "w": 4})
+
+	both := a.IntersectKeys(b)
+	sort.Strings(both)
+	if len(both) != 2 || both[0] != "y" || both[1] != "z" {
+		t.Errorf("Expected intersection [y z], got %v", both)
+	}
+	either := a.UnionKeys(b)
+	sort.Strings(either)
+	if len(either) != 4 || either[0] != "w" || either[3] != "z" {
+		t.Errorf("Expected union [w x y z], got %v", either)
+	}
+
+	if keys := a.IntersectKeys(nil); keys == nil || len(keys) != 0 {
+		t.Errorf("Expected empty intersection with nil, got %v", keys)
+	}
+	if keys := a.UnionKeys(nil); len(keys) != 3 {
+		t.Errorf("Expected union with nil to be a's keys, got %v", keys)
+	}
+	if keys := a.IntersectKeys(a); len(keys) != 3 {
+		t.Errorf("Expected intersection with itself to be a's keys, got %v", keys)
+	}
+
+	// Expired keys are treated as absent
+	expireNow(b, "z")
+	if both := a.IntersectKeys(b); fmt.Sprint(both) != "[y]" {
+		t.Errorf("Expected intersection [y], got %v", both)
+	}
+	expireNow(a, "x")
+	either = a.UnionKeys(b)
+	sort.Strings(either)
+	if fmt.Sprint(either) != "[w y z]" {
+		t.Errorf("Expected union [w y z], got %v", either)
+	}
+}
+
+func TestGetMany(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 2, "c": 3})
+
+	result := m.GetMany("a", "c", "missing")
+	if len(result) != 2 || result["a"] != 1 || result["c"] != 3 {
+		t.Errorf("Expected map[a:1 c:3], got %v", result)
+	}
+	if _, ok := result["missing"]; ok {
+		t.Errorf("Expected missing key to be absent from result")
+	}
+
+	if result := m.GetMany(); len(result) != 0 {
+		t.Errorf("Expected empty result for no keys, got %v", result)
+	}
+}
+
+func TestGetOr(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "zero": 0})
+
+	if val := m.GetOr("a", 42); val != 1 {
+		t.Errorf("Expected stored value 1, got %v", val)
+	}
+	if val := m.GetOr("zero", 42); val != 0 {
+		t.Errorf("Expected stored zero value, got %v", val)
+	}
+	if val := m.GetOr("missing", 42); val != 42 {
+		t.Errorf("Expected default 42 for missing key, got %v", val)
+	}
+	if m.Contains("missing") {
+		t.Errorf("Expected GetOr not to insert the default")
+	}
+}
+
+func TestGetOrSet(t *testing.T) {
+	m := New[string, int]()
+
+	actual, loaded := m.GetOrSet("a", 1)
+	if loaded || actual != 1 {
+		t.Errorf("GetOrSet on missing key: expected (1, false), got (%v, %v)", actual, loaded)
+	}
+
+	actual, loaded = m.GetOrSet("a", 2)
+	if !loaded || actual != 1 {
+		t.Errorf("GetOrSet on existing key: expected (1, true), got (%v, %v)", actual, loaded)
+	}
+
+	if keys := m.GetKeys(2); len(keys) != 0 {
+		t.Errorf("Expected no keys for value 2, got %v", keys)
+	}
+	if keys := m.GetKeys(1); len(keys) != 1 || keys[0] != "a" {
+		t.Errorf("Expected keys [a] for value 1, got %v", keys)
+	}
+}
+
+func TestGetOrSetConcurrent(t *testing.T) {
+	m := New[string, int]()
+	const goroutines = 20
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	stored := 0
+
+	wg.Add(goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func(id int) {
+			defer wg.Done()
+			if _, loaded := m.GetOrSet("shared", id); !loaded {
+				mu.Lock()
+				stored++
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if stored != 1 {
+		t.Errorf("Expected exactly one goroutine to store the value, got %d", stored)
+	}
+}
+
+func TestSetIfAbsent(t *testing.T) {
+	m := New[string, int]()
+
+	if !m.SetIfAbsent("a", 1) {
+		t.Errorf("SetIfAbsent on missing key: expected true, got false")
+	}
+	if m.SetIfAbsent("a", 2) {
+		t.Errorf("SetIfAbsent on existing key: expected false, got true")
+	}
+
+	if val, ok := m.Get("a"); !ok || val != 1 {
+		t.Errorf("Expected first writer's value 1, got %v, exists: %v", val, ok)
+	}
+	if m.ContainsValue(2) {
+		t.Errorf("Reverse index modified by rejected SetIfAbsent")
+	}
+	if keys := m.GetKeys(1); len(keys) != 1 || keys[0] != "a" {
+		t.Errorf("Expected keys [a] for value 1, got %v", keys)
+	}
+}
+
+func TestSetReport(t *testing.T) {
+	m := New[string, int]()
+
+	if inserted, stored := m.SetReport("a", 1); !inserted || !stored {
+		t.Errorf("SetReport on missing key: expected (true, true), got (%v, %v)", inserted, stored)
+	}
+	if inserted, stored := m.SetReport("a", 2); inserted || !stored {
+		t.Errorf("SetReport on existing key: expected (false, true), got (%v, %v)", inserted, stored)
+	}
+	if inserted, stored := m.SetReport("a", 2); inserted || !stored {
+		t.Errorf("SetReport with unchanged value: expected (false, true), got (%v, %v)", inserted, stored)
+	}
+	if val, _ := m.Get("a"); val != 2 || m.ContainsValue(1) {
+		t.Errorf("Expected a=2 with reverse index updated, got %v", val)
+	}
+
+	b := NewBounded[string, int](1)
+	b.Set("a", 1)
+	if inserted, stored := b.SetReport("b", 2); inserted || stored || b.Contains("b") {
+		t.Errorf("Expected SetReport to report a refused key on a full bounded map, got (%v, %v)", inserted, stored)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1})
+
+	if m.Replace("missing", 2) {
+		t.Errorf("Expected Replace on missing key to return false")
+	}
+	if m.Contains("missing") || m.ContainsValue(2) {
+		t.Errorf("Expected Replace not to insert a missing key")
+	}
+
+	if !m.Replace("a", 2) {
+		t.Errorf("Expected Replace on existing key to return true")
+	}
+	if val, _ := m.Get("a"); val != 2 {
+		t.Errorf("Expected a=2 after Replace, got %v", val)
+	}
+	if m.ContainsValue(1) || m.CountKeys(2) != 1 {
+		t.Errorf("Expected reverse index to move a from 1 to 2")
+	}
+}
+
+func TestSwap(t *testing.T) {
+	m := New[string, int]()
+
+	previous, loaded := m.Swap("a", 1)
+	if loaded || previous != 0 {
+		t.Errorf("Swap on missing key: expected (0, false), got (%v, %v)", previous, loaded)
+	}
+	if val, ok := m.Get("a"); !ok || val != 1 {
+		t.Errorf("Expected Swap to insert a=1, got %v, exists: %v", val, ok)
+	}
+
+	previous, loaded = m.Swap("a", 2)
+	if !loaded || previous != 1 {
+		t.Errorf("Swap on existing key: expected (1, true), got (%v, %v)", previous, loaded)
+	}
+	if m.ContainsValue(1) || m.CountKeys(2) != 1 {
+		t.Errorf("Expected reverse index to move a from 1 to 2")
+	}
+}
+
+func TestMove(t *testing.T) {
+	m := New[string, string](map[string]string{"item1": "bucketA", "item2": "bucketA"})
+
+	old, moved := m.Move("item1", "bucketB")
+	if !moved || old != "bucketA" {
+		t.Errorf("Move to new bucket: expected (bucketA, true), got (%v, %v)", old, moved)
+	}
+	if keys := m.GetKeys("bucketA"); len(keys) != 1 || keys[0] != "item2" {
+		t.Errorf("Expected keys [item2] for bucketA, got %v", keys)
+	}
+	if keys := m.GetKeys("bucketB"); len(keys) != 1 || keys[0] != "item1" {
+		t.Errorf("Expected keys [item1] for bucketB, got %v", keys)
+	}
+
+	// Moving to the current value is not a move
+	old, moved = m.Move("item1", "bucketB")
+	if moved || old != "bucketB" {
+		t.Errorf("Move to same bucket: expected (bucketB, false), got (%v, %v)", old, moved)
+	}
+
+	// A missing key is inserted
+	old, moved = m.Move("item3", "bucketC")
+	if moved || old != "" {
+		t.Errorf("Move missing key: expected (\"\", false), got (%q, %v)", old, moved)
+	}
+	if val, ok := m.Get("item3"); !ok || val != "bucketC" {
+		t.Errorf("Expected item3 to be inserted with bucketC, got %v, exists: %v", val, ok)
+	}
+}
+
+func TestSwapValue(t *testing.T) {
+	m := New[string, string](map[string]string{"a": "golang", "b": "golang", "c": "go", "d": "rust"})
+
+	var events []ChangeEvent[string, string]
+	m.OnChange(func(e ChangeEvent[string, string]) { events = append(events, e) })
+
+	if n := m.SwapValue("golang", "go"); n != 2 {
+		t.Errorf("Expected 2 keys retagged, got %d", n)
+	}
+	if m.ContainsValue("golang") {
+		t.Errorf("Expected golang to be dropped from reverse index")
+	}
+	keys := m.GetKeys("go")
+	sort.Strings(keys)
+	if len(keys) != 3 || keys[0] != "a" || keys[1] != "b" || keys[2] != "c" {
+		t.Errorf("Expected keys [a b c] for go, got %v", keys)
+	}
+	if val, _ := m.Get("a"); val != "go" {
+		t.Errorf("Expected a=go, got %v", val)
+	}
+	if len(events) != 2 || events[0].OldValue != "golang" || events[0].NewValue != "go" {
+		t.Errorf("Expected 2 set events from golang to go, got %v", events)
+	}
+
+	// Renaming into an unused value moves the whole set
+	if n := m.SwapValue("rust", "zig"); n != 1 {
+		t.Errorf("Expected 1 key retagged, got %d", n)
+	}
+	if n := m.SwapValue("missing", "go"); n != 0 {
+		t.Errorf("Expected 0 for absent value, got %d", n)
+	}
+	if n := m.SwapValue("go", "go"); n != 0 {
+		t.Errorf("Expected 0 for identical values, got %d", n)
+	}
+	if err := m.CheckIntegrity(); err != nil {
+		t.Errorf("Expected consistent map, got %v", err)
+	}
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1})
+
+	if m.CompareAndSwap("a", 2, 3) {
+		t.Errorf("Expected CompareAndSwap with wrong old value to fail")
+	}
+	if m.CompareAndSwap("missing", 0, 3) {
+		t.Errorf("Expected CompareAndSwap on missing key to fail")
+	}
+	if m.Contains("missing") || m.ContainsValue(3) {
+		t.Errorf("Expected failed CompareAndSwap to leave the map unchanged")
+	}
+
+	if !m.CompareAndSwap("a", 1, 3) {
+		t.Errorf("Expected CompareAndSwap with matching old value to succeed")
+	}
+	if val, _ := m.Get("a"); val != 3 {
+		t.Errorf("Expected a=3, got %v", val)
+	}
+	if m.ContainsValue(1) || m.CountKeys(3) != 1 {
+		t.Errorf("Expected reverse index to move a from 1 to 3")
+	}
+}
+
+func TestCompareAndSwapConcurrent(t *testing.T) {
+	m := New[string, int](map[string]int{"counter": 0})
+	const goroutines = 10
+	const increments = 50
+
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < increments; j++ {
+				for {
+					old, _ := m.Get("counter")
+					if m.CompareAndSwap("counter", old, old+1) {
+						break
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if val, _ := m.Get("counter"); val != goroutines*increments {
+		t.Errorf("Expected counter=%d, got %d", goroutines*increments, val)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	m := New[string, int]()
+
+	// Missing key is passed as zero value with exists=false
+	m.Update("hits", func(v int, exists bool) int {
+		if exists {
+			t.Errorf("Expected exists=false for missing key")
+		}
+		return v + 1
+	})
+	if val, ok := m.Get("hits"); !ok || val != 1 {
+		t.Errorf("Expected hits=1, got %v, exists: %v", val, ok)
+	}
+
+	m.Update("hits", func(v int, exists bool) int {
+		if !exists || v != 1 {
+			t.Errorf("Expected (1, true), got (%v, %v)", v, exists)
+		}
+		return v + 1
+	})
+	if m.ContainsValue(1) || m.CountKeys(2) != 1 {
+		t.Errorf("Expected reverse index to move hits from 1 to 2")
+	}
+}
+
+func TestUpdateConcurrent(t *testing.T) {
+	m := New[string, int]()
+	const goroutines = 10
+	const increments = 100
+
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < increments; j++ {
+				m.Update("counter", func(v int, _ bool) int { return v + 1 })
+			}
+		}()
+	}
+	wg.Wait()
+
+	if val, _ := m.Get("counter"); val != goroutines*increments {
+		t.Errorf("Expected counter=%d, got %d", goroutines*increments, val)
+	}
+}
+
+func TestContains(t *testing.T) {
+	m := New[string, int]()
+	m.Set("a", 1)
+	m.Set("b", 2)
+
+	if !m.Contains("a") {
+		t.Errorf("Expected Contains(a) to be true")
+	}
+	if m.Contains("z") {
+		t.Errorf("Expected Contains(z) to be false")
+	}
+	if !m.ContainsValue(2) {
+		t.Errorf("Expected ContainsValue(2) to be true")
+	}
+	if m.ContainsValue(3) {
+		t.Errorf("Expected ContainsValue(3) to be false")
+	}
+
+	m.Remove("b")
+	if m.Contains("b") || m.ContainsValue(2) {
+		t.Errorf("Expected key b and value 2 to be gone after removal")
+	}
+}
+
+func TestContainsValueNoAlloc(t *testing.T) {
+	m := New[string, string](map[string]string{"alice": "alice@example.com"})
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if !m.ContainsValue("alice@example.com") || m.ContainsValue("bob@example.com") {
+			t.Fatal("Unexpected ContainsValue result")
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Expected ContainsValue not to allocate, got %v allocations", allocs)
+	}
+}
+
 func TestReverseLookup(t *testing.T) {
 	m := New[string, int]()
 
@@ -54,18 +1126,181 @@ func TestReverseLookup(t *testing.T) {
 	m.Set("b", 2)
 	m.Set("c", 1)
 
-	keys := m.GetKeys(1)
-	if len(keys) != 2 {
-		t.Errorf("Expected 2 keys for value 1, got %d: %v", len(keys), keys)
+	keys := m.GetKeys(1)
+	if len(keys) != 2 {
+		t.Errorf("Expected 2 keys for value 1, got %d: %v", len(keys), keys)
+	}
+
+	// Ensure keys contain both "a" and "c"
+	keySet := make(map[string]bool)
+	for _, k := range keys {
+		keySet[k] = true
+	}
+	if !keySet["a"] || !keySet["c"] {
+		t.Errorf("Expected keys [a c] for value 1, got %v", keys)
+	}
+}
+
+func TestGetKeysMany(t *testing.T) {
+	m := New[string, string](map[string]string{"t1": "w1", "t2": "w1", "t3": "w2"})
+
+	result := m.GetKeysMany("w1", "w2", "w9", "w1")
+	if len(result) != 2 {
+		t.Errorf("Expected entries for w1 and w2 only, got %v", result)
+	}
+	sort.Strings(result["w1"])
+	if fmt.Sprint(result["w1"]) != "[t1 t2]" || fmt.Sprint(result["w2"]) != "[t3]" {
+		t.Errorf("Expected map[w1:[t1 t2] w2:[t3]], got %v", result)
+	}
+	if _, ok := result["w9"]; ok {
+		t.Errorf("Expected absent value to be omitted")
+	}
+
+	// The slices are copies
+	result["w2"][0] = "changed"
+	if keys := m.GetKeys("w2"); keys[0] != "t3" {
+		t.Errorf("Expected map to be unaffected by changes to the result, got %v", keys)
+	}
+	if result := m.GetKeysMany(); result == nil || len(result) != 0 {
+		t.Errorf("Expected empty non-nil map for no values, got %v", result)
+	}
+}
+
+func TestGetKeysOr(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 1})
+	def := []string{"default"}
+
+	keys := m.GetKeysOr(1, def)
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+		t.Errorf("Expected keys [a b] for value 1, got %v", keys)
+	}
+
+	got := m.GetKeysOr(2, def)
+	if len(got) != 1 || &got[0] != &def[0] {
+		t.Errorf("Expected the default slice itself for absent value, got %v", got)
+	}
+	if got := m.GetKeysOr(2, nil); got != nil {
+		t.Errorf("Expected nil default to be returned, got %v", got)
+	}
+}
+
+func TestGetKeysInto(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 1, "c": 2})
+
+	buf := make([]string, 0, 4)
+	buf = m.GetKeysInto(1, buf)
+	sort.Strings(buf)
+	if strings.Join(buf, ",") != "a,b" {
+		t.Errorf("Expected keys [a b], got %v", buf)
+	}
+
+	// The buffer is reset, not appended to
+	buf = m.GetKeysInto(2, buf)
+	if len(buf) != 1 || buf[0] != "c" {
+		t.Errorf("Expected keys [c], got %v", buf)
+	}
+	if cap(buf) != 4 {
+		t.Errorf("Expected buffer to be reused with capacity 4, got %d", cap(buf))
+	}
+
+	if buf = m.GetKeysInto(3, buf); len(buf) != 0 {
+		t.Errorf("Expected no keys for absent value, got %v", buf)
+	}
+
+	// A nil buffer is grown as needed
+	if keys := m.GetKeysInto(1, nil); len(keys) != 2 {
+		t.Errorf("Expected 2 keys with nil buffer, got %v", keys)
+	}
+}
+
+func TestGetKeysSorted(t *testing.T) {
+	m := New[string, int](map[string]int{"d": 1, "b": 1, "a": 1, "c": 1, "e": 2})
+
+	for i := 0; i < 5; i++ {
+		keys := m.GetKeysSorted(1, func(a, b string) bool { return a < b })
+		if strings.Join(keys, ",") != "a,b,c,d" {
+			t.Fatalf("Expected keys [a b c d], got %v", keys)
+		}
+	}
+
+	if keys := m.GetKeysSorted(3, func(a, b string) bool { return a < b }); len(keys) != 0 {
+		t.Errorf("Expected no keys for absent value, got %v", keys)
+	}
+}
+
+func TestGetKeysFunc(t *testing.T) {
+	m := New[int, string](map[int]string{1: "w1", 2: "w1", 3: "w1", 4: "w1", 5: "w2"})
+
+	keys := m.GetKeysFunc("w1", func(k int) bool { return k%2 == 0 })
+	sort.Ints(keys)
+	if len(keys) != 2 || keys[0] != 2 || keys[1] != 4 {
+		t.Errorf("Expected keys [2 4], got %v", keys)
+	}
+
+	if keys := m.GetKeysFunc("w1", func(int) bool { return false }); keys == nil || len(keys) != 0 {
+		t.Errorf("Expected empty non-nil slice when nothing matches, got %v", keys)
+	}
+	if keys := m.GetKeysFunc("missing", func(int) bool { return true }); keys == nil || len(keys) != 0 {
+		t.Errorf("Expected empty non-nil slice for missing value, got %v", keys)
+	}
+}
+
+func TestKeysWhere(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 10, "b": 55, "c": 70, "d": 30})
+
+	keys := m.KeysWhere(func(v int) bool { return v > 50 })
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "b" || keys[1] != "c" {
+		t.Errorf("Expected keys [b c], got %v", keys)
+	}
+	if keys := m.KeysWhere(func(v int) bool { return v > 100 }); keys == nil || len(keys) != 0 {
+		t.Errorf("Expected empty non-nil slice when nothing matches, got %v", keys)
+	}
+}
+
+func TestGetAny(t *testing.T) {
+	m := New[string, string](map[string]string{"s1": "poolA", "s2": "poolA", "s3": "poolB"})
+
+	key, ok := m.GetAny("poolA")
+	if !ok || (key != "s1" && key != "s2") {
+		t.Errorf("Expected s1 or s2 for poolA, got %q, exists: %v", key, ok)
+	}
+
+	key, ok = m.GetAny("poolC")
+	if ok || key != "" {
+		t.Errorf("Expected (\"\", false) for absent value, got (%q, %v)", key, ok)
+	}
+}
+
+func TestCountKeys(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 2, "c": 1})
+
+	if n := m.CountKeys(1); n != 2 {
+		t.Errorf("Expected 2 keys for value 1, got %d", n)
+	}
+	if n := m.CountKeys(2); n != 1 {
+		t.Errorf("Expected 1 key for value 2, got %d", n)
+	}
+	if n := m.CountKeys(3); n != 0 {
+		t.Errorf("Expected 0 keys for absent value, got %d", n)
+	}
+
+	m.Set("a", 2)
+	if m.CountKeys(1) != 1 || m.CountKeys(2) != 2 {
+		t.Errorf("Unexpected counts after update: value 1=%d, value 2=%d", m.CountKeys(1), m.CountKeys(2))
 	}
+}
 
-	// Ensure keys contain both "a" and "c"
-	keySet := make(map[string]bool)
-	for _, k := range keys {
-		keySet[k] = true
+func TestCountKeysMany(t *testing.T) {
+	m := New[string, string](map[string]string{"t1": "w1", "t2": "w1", "t3": "w2"})
+
+	counts := m.CountKeysMany("w1", "w2", "w9")
+	if len(counts) != 3 || counts["w1"] != 2 || counts["w2"] != 1 || counts["w9"] != 0 {
+		t.Errorf("Expected map[w1:2 w2:1 w9:0], got %v", counts)
 	}
-	if !keySet["a"] || !keySet["c"] {
-		t.Errorf("Expected keys [a c] for value 1, got %v", keys)
+	if counts := m.CountKeysMany(); counts == nil || len(counts) != 0 {
+		t.Errorf("Expected empty non-nil map for no values, got %v", counts)
 	}
 }
 
@@ -91,6 +1326,170 @@ func TestRemove(t *testing.T) {
 	}
 }
 
+func TestPop(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 1})
+
+	val, ok := m.Pop("a")
+	if !ok || val != 1 {
+		t.Errorf("Pop existing key: expected (1, true), got (%v, %v)", val, ok)
+	}
+	if m.Contains("a") {
+		t.Errorf("Expected key a to be removed by Pop")
+	}
+	if keys := m.GetKeys(1); len(keys) != 1 || keys[0] != "b" {
+		t.Errorf("Expected keys [b] for value 1, got %v", keys)
+	}
+
+	val, ok = m.Pop("a")
+	if ok || val != 0 {
+		t.Errorf("Pop missing key: expected (0, false), got (%v, %v)", val, ok)
+	}
+	if m.Len() != 1 {
+		t.Errorf("Expected length 1 after popping missing key, got %d", m.Len())
+	}
+}
+
+func TestPopAny(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 1, "c": 2})
+
+	seen := make(map[string]int)
+	for k, v, ok := m.PopAny(); ok; k, v, ok = m.PopAny() {
+		if _, dup := seen[k]; dup {
+			t.Errorf("Key %s popped twice", k)
+		}
+		seen[k] = v
+		if m.Contains(k) {
+			t.Errorf("Expected key %s to be removed by PopAny", k)
+		}
+	}
+	if len(seen) != 3 || seen["a"] != 1 || seen["b"] != 1 || seen["c"] != 2 {
+		t.Errorf("Expected all entries popped, got %v", seen)
+	}
+	if !m.IsEmpty() || m.LenValues() != 0 {
+		t.Errorf("Expected map and reverse index to be empty after draining")
+	}
+
+	k, v, ok := m.PopAny()
+	if ok || k != "" || v != 0 {
+		t.Errorf("PopAny on empty map: expected (\"\", 0, false), got (%q, %v, %v)", k, v, ok)
+	}
+}
+
+func TestCompareAndDelete(t *testing.T) {
+	m := New[string, string](map[string]string{"lease": "owner-2"})
+
+	// Lease was renewed by someone else: must not be reclaimed
+	if m.CompareAndDelete("lease", "owner-1") {
+		t.Errorf("Expected CompareAndDelete with stale value to fail")
+	}
+	if val, ok := m.Get("lease"); !ok || val != "owner-2" {
+		t.Errorf("Expected lease to remain with owner-2, got %v, exists: %v", val, ok)
+	}
+	if m.CompareAndDelete("missing", "") {
+		t.Errorf("Expected CompareAndDelete on missing key to fail")
+	}
+
+	if !m.CompareAndDelete("lease", "owner-2") {
+		t.Errorf("Expected CompareAndDelete with current value to succeed")
+	}
+	if m.Contains("lease") || m.ContainsValue("owner-2") {
+		t.Errorf("Expected lease to be removed from both indexes")
+	}
+}
+
+func TestRemoveValue(t *testing.T) {
+	m := New[string, string](map[string]string{
+		"task1": "worker1",
+		"task2": "worker2",
+		"task3": "worker1",
+	})
+
+	removed := m.RemoveValue("worker1")
+	sort.Strings(removed)
+	if len(removed) != 2 || removed[0] != "task1" || removed[1] != "task3" {
+		t.Errorf("Expected removed keys [task1 task3], got %v", removed)
+	}
+	if m.Len() != 1 || m.Contains("task1") || m.Contains("task3") {
+		t.Errorf("Expected only task2 to remain, got %v", m.List())
+	}
+	if m.ContainsValue("worker1") {
+		t.Errorf("Expected worker1 to be dropped from reverse index")
+	}
+
+	// Removing an absent value returns an empty, non-nil slice
+	if removed := m.RemoveValue("worker9"); removed == nil || len(removed) != 0 {
+		t.Errorf("Expected empty slice for absent value, got %#v", removed)
+	}
+}
+
+func TestRemoveValueConcurrentClaim(t *testing.T) {
+	m := New[int, string]()
+	const keys = 1000
+
+	// Writers keep adding keys to the bucket while claimers drain it
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	claimed := make(map[int]int)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < keys; i++ {
+			m.Set(i, "bucket")
+		}
+	}()
+	for c := 0; c < 4; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				for _, k := range m.RemoveValue("bucket") {
+					mu.Lock()
+					claimed[k]++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for _, k := range m.RemoveValue("bucket") {
+		claimed[k]++
+	}
+
+	if len(claimed) != keys {
+		t.Errorf("Expected all %d keys claimed, got %d", keys, len(claimed))
+	}
+	for k, n := range claimed {
+		if n != 1 {
+			t.Errorf("Expected key %d to be claimed once, got %d", k, n)
+		}
+	}
+}
+
+func TestRemoveIf(t *testing.T) {
+	m := New[string, string](map[string]string{
+		"u1": "spam",
+		"u2": "ok",
+		"u3": "spam",
+		"u4": "blocked",
+	})
+	blocklist := map[string]bool{"spam": true, "blocked": true}
+
+	removed := m.RemoveIf(func(_ string, v string) bool { return blocklist[v] })
+	if removed != 3 {
+		t.Errorf("Expected 3 entries removed, got %d", removed)
+	}
+	if m.Len() != 1 || !m.Contains("u2") {
+		t.Errorf("Expected only u2 to remain, got %v", m.List())
+	}
+	if m.LenValues() != 1 || !m.ContainsValue("ok") {
+		t.Errorf("Expected reverse index to hold only value ok, got %v", m.DistinctValues())
+	}
+
+	if removed := m.RemoveIf(func(string, string) bool { return false }); removed != 0 {
+		t.Errorf("Expected no removals, got %d", removed)
+	}
+}
+
 func TestListAndValues(t *testing.T) {
 	m := New[string, int]()
 
@@ -109,6 +1508,377 @@ func TestListAndValues(t *testing.T) {
 	}
 }
 
+func TestListPooled(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 2, "c": 1})
+
+	keys, release := m.ListPooled()
+	sort.Strings(keys)
+	if len(keys) != 3 || keys[0] != "a" || keys[1] != "b" || keys[2] != "c" {
+		t.Errorf("Expected keys [a b c], got %v", keys)
+	}
+	release()
+	release() // a second release is a no-op
+
+	m.Remove("b")
+	keys, release = m.ListPooled()
+	defer release()
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "c" {
+		t.Errorf("Expected keys [a c] after removal, got %v", keys)
+	}
+}
+
+func TestEntries(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 2, "c": 1})
+
+	entries := m.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	expected := []Pair[string, int]{{"a", 1}, {"b", 2}, {"c", 1}}
+	for i, e := range expected {
+		if entries[i] != e {
+			t.Errorf("Entry %d: expected %v, got %v", i, e, entries[i])
+		}
+	}
+
+	if entries := New[string, int]().Entries(); len(entries) != 0 {
+		t.Errorf("Expected no entries for empty map, got %v", entries)
+	}
+}
+
+func TestSortedKeysAndValues(t *testing.T) {
+	m := New[string, int](map[string]int{"c": 1, "a": 3, "b": 2, "d": 1})
+
+	keys := m.SortedKeys(func(a, b string) bool { return a < b })
+	if strings.Join(keys, ",") != "a,b,c,d" {
+		t.Errorf("Expected keys [a b c d], got %v", keys)
+	}
+
+	values := m.SortedValues(func(a, b int) bool { return a > b })
+	if fmt.Sprint(values) != "[3 2 1 1]" {
+		t.Errorf("Expected values [3 2 1 1], got %v", values)
+	}
+}
+
+func TestDistinctValues(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 2, "c": 1, "d": 1})
+
+	values := m.DistinctValues()
+	sort.Ints(values)
+	if len(values) != 2 || values[0] != 1 || values[1] != 2 {
+		t.Errorf("Expected distinct values [1 2], got %v", values)
+	}
+
+	if values := New[string, int]().DistinctValues(); len(values) != 0 {
+		t.Errorf("Expected no distinct values for empty map, got %v", values)
+	}
+}
+
+func TestDistinctValuesSkewed(t *testing.T) {
+	m := NewWithCapacity[int, string](1000)
+	for i := 0; i < 1000; i++ {
+		if i%100 == 0 {
+			m.Set(i, fmt.Sprintf("rare-%d", i))
+		} else {
+			m.Set(i, "common")
+		}
+	}
+
+	values := m.DistinctValues()
+	if len(values) != 11 {
+		t.Fatalf("Expected 11 distinct values, got %d", len(values))
+	}
+	seen := make(map[string]int)
+	for _, v := range values {
+		seen[v]++
+	}
+	for v, n := range seen {
+		if n != 1 {
+			t.Errorf("Expected value %q exactly once, got %d times", v, n)
+		}
+	}
+	if len(m.Values()) != 1000 {
+		t.Errorf("Expected Values to keep one entry per key, got %d", len(m.Values()))
+	}
+}
+
+func TestValueCounts(t *testing.T) {
+	m := New[string, string](map[string]string{"a": "x", "b": "x", "c": "y"})
+
+	counts := m.ValueCounts()
+	if len(counts) != 2 || counts["x"] != 2 || counts["y"] != 1 {
+		t.Errorf("Expected map[x:2 y:1], got %v", counts)
+	}
+
+	// The result is a copy
+	counts["x"] = 100
+	if n := m.CountKeys("x"); n != 2 {
+		t.Errorf("Expected map to be unaffected by mutating the result, got %d", n)
+	}
+
+	if counts := New[string, string]().ValueCounts(); counts == nil || len(counts) != 0 {
+		t.Errorf("Expected empty non-nil map for empty map, got %v", counts)
+	}
+}
+
+func TestValuesByFrequency(t *testing.T) {
+	m := New[string, string](map[string]string{"a": "x", "b": "x", "c": "y", "d": "x", "e": "y", "f": "z"})
+
+	ranked := m.ValuesByFrequency()
+	if fmt.Sprint(ranked) != "[{x 3} {y 2} {z 1}]" {
+		t.Errorf("Expected [{x 3} {y 2} {z 1}], got %v", ranked)
+	}
+	if ranked := New[string, string]().ValuesByFrequency(); len(ranked) != 0 {
+		t.Errorf("Expected no values for empty map, got %v", ranked)
+	}
+
+	// Equal counts are ordered by value on sorted maps
+	s := NewSorted[string, string]()
+	s.SetMany(map[string]string{"a": "q", "b": "p", "c": "r", "d": "r", "e": "o"})
+	if got := fmt.Sprint(s.ValuesByFrequency()); got != "[{r 2} {o 1} {p 1} {q 1}]" {
+		t.Errorf("Expected [{r 2} {o 1} {p 1} {q 1}], got %v", got)
+	}
+}
+
+func TestRangeKeys(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 2, "c": 1})
+
+	var keys []string
+	m.RangeKeys(1, func(key string) bool {
+		keys = append(keys, key)
+		return true
+	})
+	sort.Strings(keys)
+	if fmt.Sprint(keys) != "[a c]" {
+		t.Errorf("Expected keys [a c] for value 1, got %v", keys)
+	}
+
+	// Early termination
+	calls := 0
+	m.RangeKeys(1, func(string) bool {
+		calls++
+		return false
+	})
+	if calls != 1 {
+		t.Errorf("Expected RangeKeys to stop after 1 call, got %d", calls)
+	}
+
+	m.RangeKeys(9, func(key string) bool {
+		t.Errorf("Expected no keys for absent value, got %v", key)
+		return true
+	})
+
+	allocs := testing.AllocsPerRun(100, func() {
+		m.RangeKeys(1, func(string) bool { return true })
+	})
+	if allocs != 0 {
+		t.Errorf("Expected RangeKeys not to allocate, got %v allocs", allocs)
+	}
+}
+
+func TestForEachValue(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 2, "c": 1})
+
+	seen := make(map[int][]string)
+	m.ForEachValue(func(value int, keys []string) bool {
+		sort.Strings(keys)
+		seen[value] = keys
+		return true
+	})
+	if len(seen) != 2 || fmt.Sprint(seen[1]) != "[a c]" || fmt.Sprint(seen[2]) != "[b]" {
+		t.Errorf("Unexpected ForEachValue result: %v", seen)
+	}
+
+	// Early termination
+	calls := 0
+	m.ForEachValue(func(int, []string) bool {
+		calls++
+		return false
+	})
+	if calls != 1 {
+		t.Errorf("Expected ForEachValue to stop after 1 call, got %d", calls)
+	}
+}
+
+func TestToMap(t *testing.T) {
+	initial := map[string]int{"a": 1, "b": 2}
+	m := New[string, int](initial)
+
+	native := m.ToMap()
+	if len(native) != 2 || native["a"] != 1 || native["b"] != 2 {
+		t.Errorf("Expected %v, got %v", initial, native)
+	}
+
+	// Mutating the copy must not affect the map
+	native["c"] = 3
+	native["a"] = 10
+	if m.Len() != 2 || m.Contains("c") {
+		t.Errorf("Expected map to be unaffected by changes to the copy")
+	}
+	if val, _ := m.Get("a"); val != 1 {
+		t.Errorf("Expected a=1 after mutating the copy, got %v", val)
+	}
+
+	expireNow(m, "b")
+	if native := m.ToMap(); len(native) != 1 || native["a"] != 1 {
+		t.Errorf("Expected expired key b to be left out, got %v", native)
+	}
+}
+
+func TestRange(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 2, "c": 3})
+
+	seen := make(map[string]int)
+	m.Range(func(key string, value int) bool {
+		seen[key] = value
+		return true
+	})
+	if len(seen) != 3 || seen["a"] != 1 || seen["b"] != 2 || seen["c"] != 3 {
+		t.Errorf("Range visited unexpected pairs: %v", seen)
+	}
+
+	// Early termination
+	calls := 0
+	m.Range(func(key string, value int) bool {
+		calls++
+		return false
+	})
+	if calls != 1 {
+		t.Errorf("Expected Range to stop after 1 call, got %d", calls)
+	}
+}
+
+func TestShrinkAndCap(t *testing.T) {
+	m := NewWithCapacity[int, int](10)
+	if m.Cap() != 10 {
+		t.Errorf("Expected Cap 10 from capacity hint, got %d", m.Cap())
+	}
+
+	for i := 0; i < 100; i++ {
+		m.Set(i, i%5)
+	}
+	if m.Cap() != 100 {
+		t.Errorf("Expected Cap 100 after growth, got %d", m.Cap())
+	}
+
+	for i := 10; i < 100; i++ {
+		m.Remove(i)
+	}
+	if m.Cap() != 100 {
+		t.Errorf("Expected Cap to stay at peak 100 after removals, got %d", m.Cap())
+	}
+
+	m.Shrink()
+	if m.Cap() != 10 || m.Len() != 10 {
+		t.Errorf("Expected Cap and Len 10 after Shrink, got %d and %d", m.Cap(), m.Len())
+	}
+	for i := 0; i < 10; i++ {
+		if val, ok := m.Get(i); !ok || val != i%5 {
+			t.Errorf("Expected %d=%d after Shrink, got %v, exists: %v", i, i%5, val, ok)
+		}
+	}
+	if n := m.CountKeys(0); n != 2 {
+		t.Errorf("Expected 2 keys for value 0 after Shrink, got %d", n)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	m := NewWithCapacity[int, int](10)
+	for i := 0; i < 1000; i++ {
+		m.Set(i, i%2)
+	}
+	for i := 10; i < 1000; i++ {
+		m.Remove(i)
+	}
+
+	// Simulate a stale empty key set left behind in the reverse index
+	m.reverseMap[42] = map[int]struct{}{}
+
+	m.Compact()
+	if m.Cap() != 10 || m.Len() != 10 {
+		t.Errorf("Expected Cap and Len 10 after Compact, got %d and %d", m.Cap(), m.Len())
+	}
+	if m.LenValues() != 2 {
+		t.Errorf("Expected empty key set to be dropped, got %d values", m.LenValues())
+	}
+	if n := m.CountKeys(0); n != 5 {
+		t.Errorf("Expected 5 keys for value 0 after Compact, got %d", n)
+	}
+	if err := m.CheckIntegrity(); err != nil {
+		t.Errorf("Expected consistent map after Compact, got %v", err)
+	}
+}
+
+func TestGrow(t *testing.T) {
+	m := New[int, int](map[int]int{1: 1, 2: 1, 3: 2})
+	initialCap := m.Cap()
+
+	m.Grow(100)
+	if m.Cap() != 103 {
+		t.Errorf("Expected Cap 103 after Grow(100), got %d", m.Cap())
+	}
+	if m.Len() != 3 || m.CountKeys(1) != 2 {
+		t.Errorf("Expected entries preserved after Grow, got length %d", m.Len())
+	}
+	if err := m.CheckIntegrity(); err != nil {
+		t.Errorf("Expected consistent map after Grow, got %v", err)
+	}
+
+	// Enough room already, or a non-positive hint: nothing changes
+	m.Grow(50)
+	m.Grow(-1)
+	if m.Cap() != 103 {
+		t.Errorf("Expected Cap to stay 103, got %d", m.Cap())
+	}
+	if initialCap >= 103 {
+		t.Errorf("Expected initial Cap below 103, got %d", initialCap)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	m := New[string, int]()
+	if v := m.Version(); v != 0 {
+		t.Errorf("Expected version 0 for a new map, got %d", v)
+	}
+
+	m.Set("a", 1)
+	v := m.Version()
+	if v == 0 {
+		t.Errorf("Expected Set to bump the version")
+	}
+
+	// No-op changes leave the version alone
+	m.Set("a", 1)
+	m.Remove("missing")
+	m.Grow(100)
+	m.Shrink()
+	if got := m.Version(); got != v {
+		t.Errorf("Expected version %d after no-op changes, got %d", v, got)
+	}
+
+	for _, mutate := range []func(){
+		func() { m.Set("a", 2) },
+		func() { m.SwapValue(2, 3) },
+		func() { m.Remove("a") },
+		func() { m.Set("b", 1) },
+		func() { m.Clear() },
+		func() {
+			m.WithLock(func(data map[string]int, reverse map[int]map[string]struct{}) {
+				data["c"] = 5
+				reverse[5] = map[string]struct{}{"c": {}}
+			})
+		},
+	} {
+		mutate()
+		if got := m.Version(); got <= v {
+			t.Errorf("Expected version to grow past %d, got %d", v, got)
+		}
+		v = m.Version()
+	}
+}
+
 func TestLen(t *testing.T) {
 	m := New[string, int]()
 
@@ -133,6 +1903,142 @@ func TestLen(t *testing.T) {
 	}
 }
 
+func TestIsEmpty(t *testing.T) {
+	m := New[string, int]()
+	if !m.IsEmpty() {
+		t.Errorf("Expected new map to be empty")
+	}
+
+	m.Set("a", 1)
+	if m.IsEmpty() {
+		t.Errorf("Expected map with one entry not to be empty")
+	}
+
+	m.Clear()
+	if !m.IsEmpty() {
+		t.Errorf("Expected map to be empty after Clear")
+	}
+}
+
+func TestLenValues(t *testing.T) {
+	m := New[string, int]()
+	if m.LenValues() != 0 {
+		t.Errorf("Expected 0 distinct values, got %d", m.LenValues())
+	}
+
+	m.SetMany(map[string]int{"a": 1, "b": 2, "c": 1})
+	if m.LenValues() != 2 {
+		t.Errorf("Expected 2 distinct values, got %d", m.LenValues())
+	}
+
+	m.Remove("b")
+	if m.LenValues() != 1 {
+		t.Errorf("Expected 1 distinct value after removal, got %d", m.LenValues())
+	}
+}
+
+func TestSizes(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 1, "c": 2})
+
+	if keys, values := m.Sizes(); keys != 3 || values != 2 {
+		t.Errorf("Expected sizes (3, 2), got (%d, %d)", keys, values)
+	}
+	m.Remove("c")
+	if keys, values := m.Sizes(); keys != 2 || values != 1 {
+		t.Errorf("Expected sizes (2, 1) after removal, got (%d, %d)", keys, values)
+	}
+
+	f := NewForwardOnly[string, int]()
+	f.Set("a", 1)
+	if keys, values := f.Sizes(); keys != 1 || values != 0 {
+		t.Errorf("Expected sizes (1, 0) for a forward-only map, got (%d, %d)", keys, values)
+	}
+}
+
+func TestClear(t *testing.T) {
+	m := New[string, int]()
+
+	// Clearing an empty map is a no-op
+	m.Clear()
+	if m.Len() != 0 {
+		t.Errorf("Expected empty map after Clear, got length %d", m.Len())
+	}
+
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Set("c", 1)
+	m.Clear()
+
+	if m.Len() != 0 {
+		t.Errorf("Expected empty map after Clear, got length %d", m.Len())
+	}
+	if keys := m.GetKeys(1); len(keys) != 0 {
+		t.Errorf("Expected no keys for value 1 after Clear, got %v", keys)
+	}
+
+	// Map remains usable after Clear
+	m.Set("a", 3)
+	if val, ok := m.Get("a"); !ok || val != 3 {
+		t.Errorf("Set after Clear failed: expected 3, got %v, exists: %v", val, ok)
+	}
+	if keys := m.GetKeys(3); len(keys) != 1 || keys[0] != "a" {
+		t.Errorf("Expected keys [a] for value 3 after Clear, got %v", keys)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 2, "c": 1})
+
+	drained := m.Drain()
+	if len(drained) != 3 || drained["a"] != 1 || drained["b"] != 2 || drained["c"] != 1 {
+		t.Errorf("Expected all entries to be returned, got %v", drained)
+	}
+	if !m.IsEmpty() || m.LenValues() != 0 {
+		t.Errorf("Expected map and reverse index to be empty after Drain")
+	}
+
+	// The map stays usable and a second Drain returns nothing
+	m.Set("d", 4)
+	if drained := m.Drain(); len(drained) != 1 || drained["d"] != 4 {
+		t.Errorf("Expected map[d:4], got %v", drained)
+	}
+	if drained := m.Drain(); drained == nil || len(drained) != 0 {
+		t.Errorf("Expected empty non-nil map, got %v", drained)
+	}
+}
+
+func TestReplaceAll(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 2})
+
+	m.ReplaceAll(map[string]int{"b": 3, "c": 3})
+	if m.Len() != 2 || m.Contains("a") {
+		t.Errorf("Expected only the new entries, got %v", m.ToMap())
+	}
+	if m.ContainsValue(1) || m.ContainsValue(2) {
+		t.Errorf("Expected old values to be dropped from reverse index")
+	}
+	if n := m.CountKeys(3); n != 2 {
+		t.Errorf("Expected 2 keys for value 3, got %d", n)
+	}
+
+	// Readers never observe an empty map during a replacement
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			m.ReplaceAll(map[string]int{"x": i, "y": i})
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		if n := m.Len(); n != 2 {
+			t.Errorf("Expected length 2 during replacement, got %d", n)
+			break
+		}
+	}
+	wg.Wait()
+}
+
 func TestConcurrentAccess(t *testing.T) {
 	m := New[int, string]()
 	const goroutines = 10
@@ -0,0 +1,197 @@
+package genericmap
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MapFunc is a thread-safe, generic bidirectional map for values that are
+// not comparable, such as structs containing slices. The forward map is
+// keyed by K as in Map; the reverse index groups values into buckets by a
+// user-supplied hash and resolves collisions with a user-supplied equality
+// function.
+type MapFunc[K comparable, V any] struct {
+	data    map[K]V
+	buckets map[uint64][]*valueKeys[K, V]
+	eq      func(a, b V) bool
+	hash    func(V) uint64
+	mu      sync.RWMutex
+}
+
+// valueKeys is one distinct value of a MapFunc together with its keys.
+type valueKeys[K comparable, V any] struct {
+	value V
+	keys  map[K]struct{}
+}
+
+// NewFunc creates a new MapFunc that uses eq to compare values and hash to
+// place them in the reverse index. hash must be consistent with eq: values
+// that are equal must hash to the same number.
+func NewFunc[K comparable, V any](eq func(a, b V) bool, hash func(V) uint64) *MapFunc[K, V] {
+	return &MapFunc[K, V]{
+		data:    make(map[K]V),
+		buckets: make(map[uint64][]*valueKeys[K, V]),
+		eq:      eq,
+		hash:    hash,
+	}
+}
+
+// Set adds or updates a key-value pair in the map.
+func (m *MapFunc[K, V]) Set(key K, value V) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if oldValue, exists := m.data[key]; exists {
+		if m.eq(oldValue, value) {
+			m.data[key] = value
+			return
+		}
+		m.removeFromReverseMap(key, oldValue)
+	}
+
+	m.data[key] = value
+	h := m.hash(value)
+	if entry := m.find(h, value); entry != nil {
+		entry.keys[key] = struct{}{}
+		return
+	}
+	m.buckets[h] = append(m.buckets[h], &valueKeys[K, V]{
+		value: value,
+		keys:  map[K]struct{}{key: {}},
+	})
+}
+
+// Get retrieves the value associated with the key.
+// Returns the value and a boolean indicating if the key exists.
+func (m *MapFunc[K, V]) Get(key K) (V, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	val, ok := m.data[key]
+	return val, ok
+}
+
+// Contains reports whether the key exists in the map.
+func (m *MapFunc[K, V]) Contains(key K) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, ok := m.data[key]
+	return ok
+}
+
+// GetKeys retrieves all keys whose value is equal to the given value.
+func (m *MapFunc[K, V]) GetKeys(value V) []K {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entry := m.find(m.hash(value), value)
+	if entry == nil {
+		return []K{}
+	}
+	result := make([]K, 0, len(entry.keys))
+	for key := range entry.keys {
+		result = append(result, key)
+	}
+	return result
+}
+
+// ContainsValue reports whether at least one key maps to a value equal to
+// the given value.
+func (m *MapFunc[K, V]) ContainsValue(value V) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.find(m.hash(value), value) != nil
+}
+
+// List returns all keys in the map.
+func (m *MapFunc[K, V]) List() []K {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := make([]K, 0, len(m.data))
+	for k := range m.data {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Values returns all values in the map.
+func (m *MapFunc[K, V]) Values() []V {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	values := make([]V, 0, len(m.data))
+	for _, v := range m.data {
+		values = append(values, v)
+	}
+	return values
+}
+
+// Remove removes a key-value pair from the map.
+// Returns true if the key existed and was removed, false otherwise.
+func (m *MapFunc[K, V]) Remove(key K) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if value, exists := m.data[key]; exists {
+		delete(m.data, key)
+		m.removeFromReverseMap(key, value)
+		return true
+	}
+	return false
+}
+
+// Len returns the number of key-value pairs in the map.
+func (m *MapFunc[K, V]) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.data)
+}
+
+// String returns a string representation of the map.
+func (m *MapFunc[K, V]) String() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return fmt.Sprintf("MapFunc[%d]{%v}", len(m.data), m.data)
+}
+
+// find returns the reverse index entry for the value in bucket h, or nil.
+// This is an internal method and assumes the caller holds the lock.
+func (m *MapFunc[K, V]) find(h uint64, value V) *valueKeys[K, V] {
+	for _, entry := range m.buckets[h] {
+		if m.eq(entry.value, value) {
+			return entry
+		}
+	}
+	return nil
+}
+
+// removeFromReverseMap removes a key from the reverse index for a given
+// value, dropping the value's entry and bucket once they become empty.
+// This is an internal method and assumes the caller holds the write lock.
+func (m *MapFunc[K, V]) removeFromReverseMap(key K, value V) {
+	h := m.hash(value)
+	bucket := m.buckets[h]
+	for i, entry := range bucket {
+		if !m.eq(entry.value, value) {
+			continue
+		}
+		delete(entry.keys, key)
+		if len(entry.keys) == 0 {
+			// Swap-and-truncate removal of the empty entry
+			bucket[i] = bucket[len(bucket)-1]
+			bucket[len(bucket)-1] = nil
+			bucket = bucket[:len(bucket)-1]
+			if len(bucket) == 0 {
+				delete(m.buckets, h)
+			} else {
+				m.buckets[h] = bucket
+			}
+		}
+		return
+	}
+}
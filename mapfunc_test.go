@@ -0,0 +1,73 @@
+package genericmap
+
+import (
+	"slices"
+	"sort"
+	"testing"
+)
+
+type profile struct {
+	Name string
+	Tags []string
+}
+
+func profileEqual(a, b profile) bool {
+	return a.Name == b.Name && slices.Equal(a.Tags, b.Tags)
+}
+
+// profileHash deliberately collides for every profile to exercise bucket
+// collision handling.
+func profileHash(profile) uint64 { return 1 }
+
+func TestMapFunc(t *testing.T) {
+	m := NewFunc[int, profile](profileEqual, profileHash)
+
+	admin := profile{Name: "admin", Tags: []string{"all"}}
+	guest := profile{Name: "guest", Tags: []string{"read"}}
+
+	m.Set(1, admin)
+	m.Set(2, guest)
+	m.Set(3, profile{Name: "admin", Tags: []string{"all"}}) // equal but distinct slice
+
+	if m.Len() != 3 {
+		t.Errorf("Expected 3 items, got %d", m.Len())
+	}
+	if val, ok := m.Get(1); !ok || !profileEqual(val, admin) {
+		t.Errorf("Expected admin for key 1, got %v, exists: %v", val, ok)
+	}
+
+	keys := m.GetKeys(admin)
+	sort.Ints(keys)
+	if !slices.Equal(keys, []int{1, 3}) {
+		t.Errorf("Expected keys [1 3] for admin, got %v", keys)
+	}
+	if keys := m.GetKeys(guest); !slices.Equal(keys, []int{2}) {
+		t.Errorf("Expected keys [2] for guest, got %v", keys)
+	}
+
+	// Updating moves the key between reverse entries
+	m.Set(1, guest)
+	if keys := m.GetKeys(admin); !slices.Equal(keys, []int{3}) {
+		t.Errorf("Expected keys [3] for admin after update, got %v", keys)
+	}
+
+	// Removing the last key for a value drops it from the reverse index
+	if !m.Remove(3) {
+		t.Errorf("Expected Remove(3) to succeed")
+	}
+	if m.ContainsValue(admin) {
+		t.Errorf("Expected admin to be absent from reverse index")
+	}
+	if m.Remove(3) {
+		t.Errorf("Expected second Remove(3) to fail")
+	}
+	if !m.Contains(1) || m.Contains(3) {
+		t.Errorf("Unexpected Contains results after removal")
+	}
+	if len(m.List()) != 2 || len(m.Values()) != 2 {
+		t.Errorf("Expected 2 keys and values, got %v and %v", m.List(), m.Values())
+	}
+	if len(m.buckets[1]) != 1 {
+		t.Errorf("Expected a single live entry in the colliding bucket, got %d", len(m.buckets[1]))
+	}
+}
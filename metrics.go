@@ -0,0 +1,44 @@
+package genericmap
+
+// Metrics receives operational events from a Map. Implementations must be
+// safe for concurrent use and cheap: they are called while the map's lock
+// is held. The metrics subpackage provides an atomic counter implementation
+// that can be adapted to Prometheus or similar systems.
+type Metrics interface {
+	// IncSet is called for every key written, including writes of the
+	// value a key already holds.
+	IncSet()
+	// IncGet is called for every forward lookup with whether the key was found.
+	IncGet(hit bool)
+	// IncRemove is called for every key removed, including by expiration.
+	IncRemove()
+	// ObserveReverseFanout is called for every reverse lookup with the
+	// number of keys found for the value.
+	ObserveReverseFanout(n int)
+}
+
+// NopMetrics is a Metrics implementation that discards every event.
+// It is the default for maps not created with NewWithMetrics.
+type NopMetrics struct{}
+
+// IncSet implements Metrics.
+func (NopMetrics) IncSet() {}
+
+// IncGet implements Metrics.
+func (NopMetrics) IncGet(bool) {}
+
+// IncRemove implements Metrics.
+func (NopMetrics) IncRemove() {}
+
+// ObserveReverseFanout implements Metrics.
+func (NopMetrics) ObserveReverseFanout(int) {}
+
+// NewWithMetrics creates a new generic map that reports its operations to
+// metrics. A nil metrics is replaced by NopMetrics.
+func NewWithMetrics[K comparable, V comparable](metrics Metrics) *Map[K, V] {
+	m := newMap[K, V](0)
+	if metrics != nil {
+		m.metrics = metrics
+	}
+	return m
+}
@@ -0,0 +1,74 @@
+// Package metrics provides Metrics implementations for genericmap.
+package metrics
+
+import "sync/atomic"
+
+// Counters is a lock-free implementation of genericmap.Metrics that keeps
+// running totals in atomic counters. A zero Counters is ready to use, and a
+// single Counters may be shared by several maps.
+//
+// Counters is meant to be read periodically with Snapshot and exported by a
+// thin adapter, for example as Prometheus counter and gauge values.
+type Counters struct {
+	sets         atomic.Uint64
+	hits         atomic.Uint64
+	misses       atomic.Uint64
+	removes      atomic.Uint64
+	reverseCalls atomic.Uint64
+	reverseKeys  atomic.Uint64
+	reverseMax   atomic.Uint64
+}
+
+// Snapshot is a point-in-time copy of the values held by Counters.
+type Snapshot struct {
+	Sets    uint64 // keys written
+	Hits    uint64 // forward lookups that found the key
+	Misses  uint64 // forward lookups that did not find the key
+	Removes uint64 // keys removed
+
+	ReverseLookups uint64 // reverse lookups performed
+	ReverseKeys    uint64 // total keys returned by reverse lookups
+	ReverseMax     uint64 // largest number of keys returned by one lookup
+}
+
+// IncSet implements genericmap.Metrics.
+func (c *Counters) IncSet() { c.sets.Add(1) }
+
+// IncGet implements genericmap.Metrics.
+func (c *Counters) IncGet(hit bool) {
+	if hit {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+}
+
+// IncRemove implements genericmap.Metrics.
+func (c *Counters) IncRemove() { c.removes.Add(1) }
+
+// ObserveReverseFanout implements genericmap.Metrics.
+func (c *Counters) ObserveReverseFanout(n int) {
+	c.reverseCalls.Add(1)
+	c.reverseKeys.Add(uint64(n))
+	for {
+		current := c.reverseMax.Load()
+		if uint64(n) <= current || c.reverseMax.CompareAndSwap(current, uint64(n)) {
+			return
+		}
+	}
+}
+
+// Snapshot returns the current counter values. Each value is read
+// atomically, but the snapshot as a whole is not taken atomically with
+// respect to concurrent updates.
+func (c *Counters) Snapshot() Snapshot {
+	return Snapshot{
+		Sets:           c.sets.Load(),
+		Hits:           c.hits.Load(),
+		Misses:         c.misses.Load(),
+		Removes:        c.removes.Load(),
+		ReverseLookups: c.reverseCalls.Load(),
+		ReverseKeys:    c.reverseKeys.Load(),
+		ReverseMax:     c.reverseMax.Load(),
+	}
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestCounters(t *testing.T) {
+	var c Counters
+
+	c.IncSet()
+	c.IncSet()
+	c.IncGet(true)
+	c.IncGet(false)
+	c.IncGet(false)
+	c.IncRemove()
+	c.ObserveReverseFanout(3)
+	c.ObserveReverseFanout(7)
+	c.ObserveReverseFanout(0)
+
+	expected := Snapshot{
+		Sets:           2,
+		Hits:           1,
+		Misses:         2,
+		Removes:        1,
+		ReverseLookups: 3,
+		ReverseKeys:    10,
+		ReverseMax:     7,
+	}
+	if got := c.Snapshot(); got != expected {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestCountersConcurrent(t *testing.T) {
+	var c Counters
+	const goroutines = 10
+	const ops = 100
+
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func(id int) {
+			defer wg.Done()
+			for j := 0; j < ops; j++ {
+				c.IncSet()
+				c.ObserveReverseFanout(id*ops + j)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	snap := c.Snapshot()
+	if snap.Sets != goroutines*ops {
+		t.Errorf("Expected %d sets, got %d", goroutines*ops, snap.Sets)
+	}
+	if snap.ReverseMax != goroutines*ops-1 {
+		t.Errorf("Expected max fanout %d, got %d", goroutines*ops-1, snap.ReverseMax)
+	}
+}
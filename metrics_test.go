@@ -0,0 +1,45 @@
+package genericmap
+
+import (
+	"testing"
+
+	"github.com/costa92/genericmap/metrics"
+)
+
+var _ Metrics = (*metrics.Counters)(nil)
+
+func TestNewWithMetrics(t *testing.T) {
+	var counters metrics.Counters
+	m := NewWithMetrics[string, int](&counters)
+
+	m.Set("a", 1)
+	m.Set("b", 1)
+	m.Get("a")
+	m.Get("missing")
+	m.GetOr("missing", 0)
+	m.GetKeys(1)
+	m.Remove("a")
+	m.Remove("missing")
+
+	snap := counters.Snapshot()
+	if snap.Sets != 2 || snap.Hits != 1 || snap.Misses != 2 || snap.Removes != 1 {
+		t.Errorf("Unexpected counters: %+v", snap)
+	}
+	if snap.ReverseLookups != 1 || snap.ReverseKeys != 2 {
+		t.Errorf("Expected one reverse lookup returning 2 keys, got %+v", snap)
+	}
+
+	// Clear counts every key it drops
+	m.Set("c", 2)
+	m.Clear()
+	if snap := counters.Snapshot(); snap.Removes != 3 {
+		t.Errorf("Expected Clear to count 2 more removes, got %+v", snap)
+	}
+
+	// A nil Metrics falls back to the no-op implementation
+	m = NewWithMetrics[string, int](nil)
+	m.Set("a", 1)
+	if _, ok := m.Get("a"); !ok {
+		t.Errorf("Expected map with nil metrics to work normally")
+	}
+}
@@ -0,0 +1,24 @@
+package genericmap
+
+// NewWithKeyNormalizer creates a new generic map that passes every key it
+// receives through norm before using it, for example strings.ToLower for
+// case-insensitive keys. Writes store the normalized key, and lookups and
+// removals normalize their argument first, so "Alice" and "alice" refer to
+// the same entry.
+//
+// norm must be idempotent (norm(norm(k)) == norm(k)) and must not call
+// methods on the map. Methods returning keys, such as List or GetKeys,
+// return them in normalized form.
+func NewWithKeyNormalizer[K comparable, V comparable](norm func(K) K) *Map[K, V] {
+	m := newMap[K, V](0)
+	m.normalize = norm
+	return m
+}
+
+// normKey returns the form under which key is stored.
+func (m *Map[K, V]) normKey(key K) K {
+	if m.normalize == nil {
+		return key
+	}
+	return m.normalize(key)
+}
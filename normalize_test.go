@@ -0,0 +1,72 @@
+package genericmap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewWithKeyNormalizer(t *testing.T) {
+	m := NewWithKeyNormalizer[string, int](strings.ToLower)
+
+	m.Set("Alice", 1)
+	m.Set("ALICE", 2)
+	m.SetMany(map[string]int{"Bob": 3})
+
+	if m.Len() != 2 {
+		t.Errorf("Expected 2 items, got %d", m.Len())
+	}
+	if val, ok := m.Get("aLiCe"); !ok || val != 2 {
+		t.Errorf("Expected aLiCe=2, got %v, exists: %v", val, ok)
+	}
+	if !m.Contains("BOB") {
+		t.Errorf("Expected BOB to be found")
+	}
+	if keys := m.GetKeys(2); len(keys) != 1 || keys[0] != "alice" {
+		t.Errorf("Expected normalized keys [alice], got %v", keys)
+	}
+	if got := m.GetMany("Bob", "Carol"); len(got) != 1 || got["Bob"] != 3 {
+		t.Errorf("Expected GetMany keyed by the requested key, got %v", got)
+	}
+
+	m.Transaction(func(tx *Tx[string, int]) {
+		tx.Set("Carol", 4)
+	})
+	if !m.Remove("CAROL") || !m.Remove("Bob") {
+		t.Errorf("Expected removals to match normalized keys")
+	}
+	if keys := m.List(); len(keys) != 1 || keys[0] != "alice" {
+		t.Errorf("Expected List to return [alice], got %v", keys)
+	}
+}
+
+func TestKeyNormalizerDecode(t *testing.T) {
+	src := New[string, int](map[string]int{"Alice": 1, "BOB": 2})
+	gobData, err := src.GobEncode()
+	if err != nil {
+		t.Fatalf("GobEncode failed: %v", err)
+	}
+	binData, err := src.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+
+	decoders := map[string]func(m *Map[string, int]) error{
+		"GobDecode":       func(m *Map[string, int]) error { return m.GobDecode(gobData) },
+		"UnmarshalBinary": func(m *Map[string, int]) error { return m.UnmarshalBinary(binData) },
+		"ReadJSON": func(m *Map[string, int]) error {
+			return m.ReadJSON(strings.NewReader(`{"Alice":1,"BOB":2}`))
+		},
+	}
+	for name, decode := range decoders {
+		m := NewWithKeyNormalizer[string, int](strings.ToLower)
+		if err := decode(m); err != nil {
+			t.Fatalf("%s failed: %v", name, err)
+		}
+		if !m.Contains("Alice") || !m.Contains("bob") {
+			t.Errorf("Expected %s to store normalized keys, got %v", name, m.List())
+		}
+		if keys := m.GetKeys(1); len(keys) != 1 || keys[0] != "alice" {
+			t.Errorf("Expected %s to index normalized keys [alice], got %v", name, keys)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package genericmap
+
+import "container/list"
+
+// NewOrdered creates a new generic map that remembers the order in which
+// keys were inserted, as reported by OrderedList.
+//
+// Updating an existing key keeps its original position; a key only moves to
+// the end when it is removed and inserted again. Removal is O(1), as the
+// order is kept in a doubly linked list indexed by key.
+func NewOrdered[K comparable, V comparable]() *Map[K, V] {
+	m := newMap[K, V](0)
+	m.order = list.New()
+	m.elems = make(map[K]*list.Element)
+	return m
+}
+
+// OrderedList returns all keys in insertion order, oldest first.
+// It panics if the map was not created with NewOrdered.
+func (m *Map[K, V]) OrderedList() []K {
+	if traceEnabled {
+		defer traceStart("OrderedList")()
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.order == nil {
+		panic("genericmap: OrderedList requires a map created with NewOrdered")
+	}
+	keys := make([]K, 0, m.order.Len())
+	for e := m.order.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.Value.(K))
+	}
+	return keys
+}
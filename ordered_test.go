@@ -0,0 +1,53 @@
+package genericmap
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestOrderedList(t *testing.T) {
+	m := NewOrdered[string, int]()
+
+	m.Set("c", 1)
+	m.Set("a", 2)
+	m.Set("b", 3)
+	if got := fmt.Sprint(m.OrderedList()); got != "[c a b]" {
+		t.Errorf("Expected insertion order [c a b], got %v", got)
+	}
+
+	// Updating keeps the original position
+	m.Set("c", 10)
+	if got := fmt.Sprint(m.OrderedList()); got != "[c a b]" {
+		t.Errorf("Expected update to keep order [c a b], got %v", got)
+	}
+
+	// Re-inserting after removal moves the key to the end
+	m.Remove("c")
+	m.Set("c", 1)
+	if got := fmt.Sprint(m.OrderedList()); got != "[a b c]" {
+		t.Errorf("Expected re-insert to append [a b c], got %v", got)
+	}
+
+	m.RemoveValue(3)
+	if got := fmt.Sprint(m.OrderedList()); got != "[a c]" {
+		t.Errorf("Expected [a c] after RemoveValue, got %v", got)
+	}
+
+	m.Clear()
+	if got := m.OrderedList(); len(got) != 0 {
+		t.Errorf("Expected empty order after Clear, got %v", got)
+	}
+	m.Set("z", 1)
+	if got := fmt.Sprint(m.OrderedList()); got != "[z]" {
+		t.Errorf("Expected [z] after Clear and Set, got %v", got)
+	}
+}
+
+func TestOrderedListPanicsOnUnorderedMap(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected OrderedList to panic on a map without ordering")
+		}
+	}()
+	New[string, int]().OrderedList()
+}
@@ -0,0 +1,58 @@
+package genericmap
+
+import (
+	"slices"
+	"strings"
+)
+
+// NewWithPrefixIndex creates a new generic map with string-like keys that
+// also keeps its keys in a sorted slice, so that KeysWithPrefix can answer
+// prefix queries without scanning every key.
+//
+// Lookups are unaffected, but inserting or removing a key costs O(n) to
+// keep the slice sorted; updating the value of an existing key does not.
+func NewWithPrefixIndex[K ~string, V comparable]() *Map[K, V] {
+	m := newMap[K, V](0)
+	m.compare = func(a, b K) int { return strings.Compare(string(a), string(b)) }
+	m.hasPrefix = func(key, prefix K) bool { return strings.HasPrefix(string(key), string(prefix)) }
+	return m
+}
+
+// KeysWithPrefix returns, in ascending order, every key that starts with
+// prefix. It runs in O(log n + k) for k matching keys. It panics if the map
+// was not created with NewWithPrefixIndex.
+func (m *Map[K, V]) KeysWithPrefix(prefix K) []K {
+	if traceEnabled {
+		defer traceStart("KeysWithPrefix")()
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.hasPrefix == nil {
+		panic("genericmap: KeysWithPrefix requires a map created with NewWithPrefixIndex")
+	}
+	start, _ := slices.BinarySearchFunc(m.sortedKeys, prefix, m.compare)
+	result := []K{}
+	for _, key := range m.sortedKeys[start:] {
+		if !m.hasPrefix(key, prefix) {
+			break
+		}
+		result = append(result, key)
+	}
+	return result
+}
+
+// insertSortedLocked adds a new key to the sorted key index.
+// This is an internal method and assumes the caller holds the write lock.
+func (m *Map[K, V]) insertSortedLocked(key K) {
+	i, _ := slices.BinarySearchFunc(m.sortedKeys, key, m.compare)
+	m.sortedKeys = slices.Insert(m.sortedKeys, i, key)
+}
+
+// removeSortedLocked drops a key from the sorted key index.
+// This is an internal method and assumes the caller holds the write lock.
+func (m *Map[K, V]) removeSortedLocked(key K) {
+	if i, found := slices.BinarySearchFunc(m.sortedKeys, key, m.compare); found {
+		m.sortedKeys = slices.Delete(m.sortedKeys, i, i+1)
+	}
+}
@@ -0,0 +1,63 @@
+package genericmap
+
+import "testing"
+
+func TestKeysWithPrefix(t *testing.T) {
+	m := NewWithPrefixIndex[string, int]()
+	for i, word := range []string{"apple", "banana", "app", "application", "apricot", "ap"} {
+		m.Set(word, i)
+	}
+
+	expected := []string{"app", "apple", "application"}
+	keys := m.KeysWithPrefix("app")
+	if len(keys) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, keys)
+	}
+	for i := range expected {
+		if keys[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, keys)
+			break
+		}
+	}
+
+	m.Remove("apple")
+	m.Set("app", 100) // updating a value does not duplicate the key
+	if keys := m.KeysWithPrefix("app"); len(keys) != 2 || keys[0] != "app" || keys[1] != "application" {
+		t.Errorf("Expected [app application] after removal, got %v", keys)
+	}
+	if keys := m.KeysWithPrefix(""); len(keys) != 5 {
+		t.Errorf("Expected empty prefix to match all 5 keys, got %v", keys)
+	}
+	if keys := m.KeysWithPrefix("zz"); keys == nil || len(keys) != 0 {
+		t.Errorf("Expected empty non-nil slice for unmatched prefix, got %v", keys)
+	}
+	if err := m.CheckIntegrity(); err != nil {
+		t.Errorf("Expected consistent map, got %v", err)
+	}
+
+	m.Clear()
+	if keys := m.KeysWithPrefix(""); len(keys) != 0 {
+		t.Errorf("Expected no keys after Clear, got %v", keys)
+	}
+}
+
+func TestKeysWithPrefixStringLike(t *testing.T) {
+	type path string
+	m := NewWithPrefixIndex[path, bool]()
+	m.Set("/usr/bin", true)
+	m.Set("/usr/lib", true)
+	m.Set("/var/log", false)
+
+	if keys := m.KeysWithPrefix("/usr/"); len(keys) != 2 || keys[0] != "/usr/bin" {
+		t.Errorf("Expected [/usr/bin /usr/lib], got %v", keys)
+	}
+}
+
+func TestKeysWithPrefixRequiresIndex(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected KeysWithPrefix to panic on a map without a prefix index")
+		}
+	}()
+	New[string, int]().KeysWithPrefix("a")
+}
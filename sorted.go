@@ -0,0 +1,34 @@
+package genericmap
+
+import (
+	"cmp"
+	"slices"
+)
+
+// NewSorted creates a new generic map whose accessors return keys and
+// values in ascending order, so results are deterministic without sorting
+// at every call site. It applies to GetKeys, GetKeysInto, GetKeysFunc,
+// KeysWhere, List, ListPooled, Values and DistinctValues, as well as the
+// Keys and ValuesSeq iterators built on them, and orders values with equal
+// counts in ValuesByFrequency. Each such call pays an extra O(k log k) sort
+// of its result.
+func NewSorted[K cmp.Ordered, V cmp.Ordered]() *Map[K, V] {
+	m := newMap[K, V](0)
+	m.sortKeys = slices.Sort[[]K]
+	m.sortValues = slices.Sort[[]V]
+	return m
+}
+
+// orderKeys sorts keys in place if the map was created with NewSorted.
+func (m *Map[K, V]) orderKeys(keys []K) {
+	if m.sortKeys != nil {
+		m.sortKeys(keys)
+	}
+}
+
+// orderValues sorts values in place if the map was created with NewSorted.
+func (m *Map[K, V]) orderValues(values []V) {
+	if m.sortValues != nil {
+		m.sortValues(values)
+	}
+}
@@ -0,0 +1,37 @@
+package genericmap
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNewSorted(t *testing.T) {
+	m := NewSorted[string, int]()
+	for i, k := range []string{"delta", "alpha", "echo", "charlie", "bravo"} {
+		m.Set(k, 10-i%3)
+	}
+
+	if got := m.List(); !slices.Equal(got, []string{"alpha", "bravo", "charlie", "delta", "echo"}) {
+		t.Errorf("Expected sorted keys, got %v", got)
+	}
+	if got := m.Values(); !slices.IsSorted(got) || len(got) != 5 {
+		t.Errorf("Expected 5 sorted values, got %v", got)
+	}
+	if got := m.DistinctValues(); !slices.Equal(got, []int{8, 9, 10}) {
+		t.Errorf("Expected distinct values [8 9 10], got %v", got)
+	}
+	if got := m.GetKeys(10); !slices.Equal(got, []string{"charlie", "delta"}) {
+		t.Errorf("Expected keys [charlie delta] for value 10, got %v", got)
+	}
+	if got := slices.Collect(m.Keys()); !slices.IsSorted(got) {
+		t.Errorf("Expected Keys iterator to yield sorted keys, got %v", got)
+	}
+	if got := m.KeysWhere(func(v int) bool { return v < 10 }); !slices.Equal(got, []string{"alpha", "bravo", "echo"}) {
+		t.Errorf("Expected sorted KeysWhere result, got %v", got)
+	}
+	keys, release := m.ListPooled()
+	if !slices.Equal(keys, []string{"alpha", "bravo", "charlie", "delta", "echo"}) {
+		t.Errorf("Expected ListPooled to return sorted keys, got %v", keys)
+	}
+	release()
+}
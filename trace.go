@@ -0,0 +1,36 @@
+package genericmap
+
+import "time"
+
+// traceBuckets is the number of latency buckets in a TraceStat histogram.
+const traceBuckets = 16
+
+// TraceStat summarizes the latency of one Map method, as reported by
+// TraceStats.
+type TraceStat struct {
+	Count uint64        // number of calls
+	Total time.Duration // sum of all call durations
+	Max   time.Duration // slowest call
+
+	// Buckets is a latency histogram with power-of-two bounds: Buckets[0]
+	// counts calls faster than 1µs, Buckets[i] calls taking at least
+	// 2^(i-1)µs but less than 2^iµs, and the last bucket every slower call.
+	Buckets [traceBuckets]uint64
+}
+
+// Mean returns the average call duration, or 0 if there were no calls.
+func (s TraceStat) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+// traceBucket returns the histogram bucket for a call duration.
+func traceBucket(d time.Duration) int {
+	i := 0
+	for bound := time.Microsecond; d >= bound && i < traceBuckets-1; bound *= 2 {
+		i++
+	}
+	return i
+}
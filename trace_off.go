@@ -0,0 +1,24 @@
+//go:build !genericmap_trace
+
+package genericmap
+
+// traceEnabled reports whether Map methods record their latency. It is set
+// by building with the genericmap_trace tag.
+const traceEnabled = false
+
+// traceStart is never called when tracing is disabled: every call site is
+// guarded by traceEnabled, so the compiler removes it.
+func traceStart(string) func() { return func() {} }
+
+// TraceStats returns the latency recorded for every exported Map method
+// called since the program started or since the last ResetTraceStats,
+// keyed by method name and aggregated over all maps. Durations include
+// time spent waiting for the lock and in nested method calls.
+//
+// Latency is only recorded when the package is built with the
+// genericmap_trace build tag; otherwise TraceStats returns an empty map and
+// the instrumentation is compiled out entirely.
+func TraceStats() map[string]TraceStat { return map[string]TraceStat{} }
+
+// ResetTraceStats discards all latency recorded so far.
+func ResetTraceStats() {}
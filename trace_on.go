@@ -0,0 +1,66 @@
+//go:build genericmap_trace
+
+package genericmap
+
+import (
+	"sync"
+	"time"
+)
+
+// traceEnabled reports whether Map methods record their latency. It is set
+// by building with the genericmap_trace tag.
+const traceEnabled = true
+
+var (
+	traceMu    sync.Mutex
+	traceStats = make(map[string]*TraceStat)
+)
+
+// traceStart starts timing a call to the named method and returns the
+// function that records its duration.
+func traceStart(method string) func() {
+	start := time.Now()
+	return func() {
+		d := time.Since(start)
+
+		traceMu.Lock()
+		defer traceMu.Unlock()
+
+		s := traceStats[method]
+		if s == nil {
+			s = &TraceStat{}
+			traceStats[method] = s
+		}
+		s.Count++
+		s.Total += d
+		s.Max = max(s.Max, d)
+		s.Buckets[traceBucket(d)]++
+	}
+}
+
+// TraceStats returns the latency recorded for every exported Map method
+// called since the program started or since the last ResetTraceStats,
+// keyed by method name and aggregated over all maps. Durations include
+// time spent waiting for the lock and in nested method calls.
+//
+// Latency is only recorded when the package is built with the
+// genericmap_trace build tag; otherwise TraceStats returns an empty map and
+// the instrumentation is compiled out entirely.
+func TraceStats() map[string]TraceStat {
+	traceMu.Lock()
+	defer traceMu.Unlock()
+
+	result := make(map[string]TraceStat, len(traceStats))
+	for method, s := range traceStats {
+		result[method] = *s
+	}
+	return result
+}
+
+// ResetTraceStats discards all latency recorded so far.
+func ResetTraceStats() {
+	traceMu.Lock()
+	defer traceMu.Unlock()
+
+	clear(traceStats)
+}
@@ -0,0 +1,34 @@
+//go:build genericmap_trace
+
+package genericmap
+
+import "testing"
+
+func TestTraceStats(t *testing.T) {
+	ResetTraceStats()
+
+	m := New[string, int]()
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Get("a")
+
+	stats := TraceStats()
+	if s := stats["Set"]; s.Count != 2 {
+		t.Errorf("Expected 2 Set calls, got %d", s.Count)
+	}
+	if s := stats["Get"]; s.Count != 1 || s.Max < 0 || s.Total < s.Max {
+		t.Errorf("Unexpected Get stats: %+v", s)
+	}
+	var bucketed uint64
+	for _, n := range stats["Set"].Buckets {
+		bucketed += n
+	}
+	if bucketed != 2 {
+		t.Errorf("Expected 2 Set calls in the histogram, got %d", bucketed)
+	}
+
+	ResetTraceStats()
+	if stats := TraceStats(); len(stats) != 0 {
+		t.Errorf("Expected no stats after reset, got %v", stats)
+	}
+}
@@ -0,0 +1,35 @@
+package genericmap
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTraceBucket(t *testing.T) {
+	cases := []struct {
+		d      time.Duration
+		bucket int
+	}{
+		{0, 0},
+		{999 * time.Nanosecond, 0},
+		{time.Microsecond, 1},
+		{3 * time.Microsecond, 2},
+		{4 * time.Microsecond, 3},
+		{time.Hour, traceBuckets - 1},
+	}
+	for _, c := range cases {
+		if got := traceBucket(c.d); got != c.bucket {
+			t.Errorf("traceBucket(%v): expected %d, got %d", c.d, c.bucket, got)
+		}
+	}
+}
+
+func TestTraceStatMean(t *testing.T) {
+	if mean := (TraceStat{}).Mean(); mean != 0 {
+		t.Errorf("Expected zero mean without calls, got %v", mean)
+	}
+	s := TraceStat{Count: 4, Total: 10 * time.Microsecond}
+	if mean := s.Mean(); mean != 2500*time.Nanosecond {
+		t.Errorf("Expected mean 2.5µs, got %v", mean)
+	}
+}
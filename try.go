@@ -0,0 +1,45 @@
+package genericmap
+
+// TrySet adds or updates a key-value pair only if the write lock can be
+// acquired immediately. It returns false, without writing, if the lock is
+// currently held by another goroutine or the map refuses the write, as
+// described for Offer.
+func (m *Map[K, V]) TrySet(key K, value V) bool {
+	if traceEnabled {
+		defer traceStart("TrySet")()
+	}
+	key = m.normKey(key)
+	if !m.mu.TryLock() {
+		return false
+	}
+	m.checkFrozenLocked()
+	defer m.unlock()
+
+	return m.setLocked(key, value)
+}
+
+// TryGet retrieves the value for the key only if the read lock can be
+// acquired immediately.
+//
+// The two booleans mean different things and must not be confused:
+// acquired reports whether the lookup ran at all, and exists reports whether
+// the key was found. When acquired is false the map was busy, exists is
+// always false, and nothing can be concluded about the key.
+func (m *Map[K, V]) TryGet(key K) (value V, exists bool, acquired bool) {
+	if traceEnabled {
+		defer traceStart("TryGet")()
+	}
+	key = m.normKey(key)
+	if !m.mu.TryRLock() {
+		return value, false, false
+	}
+	defer m.mu.RUnlock()
+
+	value, exists = m.data[key]
+	if exists && m.expiredLocked(key) {
+		var zero V
+		value, exists = zero, false
+	}
+	m.metrics.IncGet(exists)
+	return value, exists, true
+}
@@ -0,0 +1,53 @@
+package genericmap
+
+import "testing"
+
+func TestTrySet(t *testing.T) {
+	m := New[string, int]()
+
+	if !m.TrySet("a", 1) {
+		t.Fatalf("Expected TrySet to succeed on an idle map")
+	}
+	if val, ok := m.Get("a"); !ok || val != 1 {
+		t.Errorf("Expected a=1, got %v, exists: %v", val, ok)
+	}
+
+	m.mu.RLock()
+	ok := m.TrySet("b", 2)
+	m.mu.RUnlock()
+	if ok {
+		t.Errorf("Expected TrySet to fail while the lock is held")
+	}
+	if m.Contains("b") {
+		t.Errorf("Expected failed TrySet not to write")
+	}
+}
+
+func TestTryGet(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1})
+
+	val, exists, acquired := m.TryGet("a")
+	if !acquired || !exists || val != 1 {
+		t.Errorf("Expected (1, true, true), got (%v, %v, %v)", val, exists, acquired)
+	}
+
+	val, exists, acquired = m.TryGet("missing")
+	if !acquired || exists || val != 0 {
+		t.Errorf("Expected (0, false, true) for missing key, got (%v, %v, %v)", val, exists, acquired)
+	}
+
+	// Readers don't block each other
+	m.mu.RLock()
+	_, _, acquired = m.TryGet("a")
+	m.mu.RUnlock()
+	if !acquired {
+		t.Errorf("Expected TryGet to succeed alongside another reader")
+	}
+
+	m.mu.Lock()
+	val, exists, acquired = m.TryGet("a")
+	m.mu.Unlock()
+	if acquired || exists || val != 0 {
+		t.Errorf("Expected (0, false, false) while write-locked, got (%v, %v, %v)", val, exists, acquired)
+	}
+}
@@ -0,0 +1,177 @@
+package genericmap
+
+import "time"
+
+// NewWithTTL creates a new generic map whose entries expire ttl after they
+// were last set.
+//
+// Expired entries are treated as absent by Get, Contains and the other
+// single-key operations, and Get lazily deletes them. Reverse lookups such
+// as GetKeys, GetAny, CountKeys and ContainsValue skip expired keys too. A
+// background sweeper periodically purges expired entries from both the
+// forward map and the reverse index; until it runs, bulk accessors such as
+// Len, List and Values may still report entries that have expired. Call
+// Close to stop the sweeper once the map is no longer needed.
+//
+// A ttl of zero or less disables expiration and starts no sweeper.
+func NewWithTTL[K comparable, V comparable](ttl time.Duration) *Map[K, V] {
+	m := newMap[K, V](0)
+	if ttl <= 0 {
+		return m
+	}
+
+	m.ttl = ttl
+	m.expires = make(map[K]time.Time)
+	m.stop = make(chan struct{})
+
+	interval := ttl / 2
+	if interval < time.Millisecond {
+		interval = time.Millisecond
+	}
+	go m.sweep(interval)
+	return m
+}
+
+// SetWithTTL adds or updates a key-value pair that expires ttl from now,
+// overriding the map-wide default set by NewWithTTL. A ttl of zero or less
+// stores an entry that never expires.
+//
+// SetWithTTL works on any map. On maps created without NewWithTTL there is
+// no background sweeper, so expired entries are only dropped lazily by Get
+// and the other single-key operations. Reverse lookups skip them either
+// way.
+func (m *Map[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	if traceEnabled {
+		defer traceStart("SetWithTTL")()
+	}
+	key = m.normKey(key)
+	m.lock()
+	defer m.unlock()
+
+	if m.setLocked(key, value) {
+		m.setDeadlineLocked(key, ttl)
+	}
+}
+
+// Close stops the background sweeper started by NewWithTTL.
+// The map stays usable afterwards, but expired entries are then only
+// dropped lazily. Close is safe to call more than once and is a no-op for
+// maps without a sweeper.
+func (m *Map[K, V]) Close() {
+	if traceEnabled {
+		defer traceStart("Close")()
+	}
+	m.stopOnce.Do(func() {
+		if m.stop != nil {
+			close(m.stop)
+		}
+	})
+}
+
+// sweep purges expired entries every interval until Close is called.
+func (m *Map[K, V]) sweep(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			// Not m.lock, which panics once the map has been frozen
+			m.mu.Lock()
+			if !m.frozen.Load() {
+				m.purgeExpiredLocked()
+			}
+			m.unlock()
+		}
+	}
+}
+
+// purgeExpiredLocked removes every expired entry and returns how many were
+// removed. This is an internal method and assumes the caller holds the
+// write lock.
+func (m *Map[K, V]) purgeExpiredLocked() int {
+	now := time.Now()
+	removed := 0
+	for key, deadline := range m.expires {
+		if !now.Before(deadline) {
+			m.deleteLocked(key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// setDeadlineLocked sets the key to expire ttl from now, or clears its
+// deadline when ttl is zero. This is an internal method and assumes the
+// caller holds the write lock.
+func (m *Map[K, V]) setDeadlineLocked(key K, ttl time.Duration) {
+	if ttl <= 0 {
+		delete(m.expires, key)
+		return
+	}
+	if m.expires == nil {
+		m.expires = make(map[K]time.Time)
+	}
+	m.expires[key] = time.Now().Add(ttl)
+}
+
+// expiredLocked reports whether the key has a deadline that has passed.
+// This is an internal method and assumes the caller holds the lock.
+func (m *Map[K, V]) expiredLocked(key K) bool {
+	if m.expires == nil {
+		return false
+	}
+	deadline, ok := m.expires[key]
+	return ok && !time.Now().Before(deadline)
+}
+
+// expireLocked deletes the key if it has expired, so that the operations
+// that follow see it as absent. This is an internal method and assumes the
+// caller holds the write lock.
+func (m *Map[K, V]) expireLocked(key K) {
+	if m.expiredLocked(key) {
+		m.deleteLocked(key)
+	}
+}
+
+// liveLenLocked returns the number of entries that have not expired.
+// This is an internal method and assumes the caller holds the lock.
+func (m *Map[K, V]) liveLenLocked() int {
+	n := len(m.data)
+	for k := range m.expires {
+		if m.expiredLocked(k) {
+			n--
+		}
+	}
+	return n
+}
+
+// liveKeyLocked returns an arbitrary key in keyMap that has not expired.
+// This is an internal method and assumes the caller holds the lock.
+func (m *Map[K, V]) liveKeyLocked(keyMap map[K]struct{}) (K, bool) {
+	for k := range keyMap {
+		if !m.expiredLocked(k) {
+			return k, true
+		}
+	}
+	var zero K
+	return zero, false
+}
+
+// liveCountLocked returns the number of keys in keyMap that have not
+// expired. It is O(1) on maps without deadlines.
+// This is an internal method and assumes the caller holds the lock.
+func (m *Map[K, V]) liveCountLocked(keyMap map[K]struct{}) int {
+	if m.expires == nil {
+		return len(keyMap)
+	}
+	n := 0
+	for k := range keyMap {
+		if !m.expiredLocked(k) {
+			n++
+		}
+	}
+	return n
+}
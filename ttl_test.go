@@ -0,0 +1,227 @@
+package genericmap
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTTLLazyExpiration(t *testing.T) {
+	m := NewWithTTL[string, int](time.Hour)
+	defer m.Close()
+
+	m.Set("a", 1)
+	m.Set("b", 1)
+	if val, ok := m.Get("a"); !ok || val != 1 {
+		t.Fatalf("Expected a=1 before expiration, got %v, exists: %v", val, ok)
+	}
+
+	// Force the deadline into the past instead of sleeping for the full TTL
+	m.mu.Lock()
+	m.expires["a"] = time.Now().Add(-time.Second)
+	m.mu.Unlock()
+
+	if m.Contains("a") {
+		t.Errorf("Expected Contains to treat expired key as absent")
+	}
+	if val, ok := m.Get("a"); ok || val != 0 {
+		t.Errorf("Expected expired key to be absent, got %v, exists: %v", val, ok)
+	}
+	// Get deletes the stale entry from both indexes
+	if m.Len() != 1 {
+		t.Errorf("Expected expired entry to be deleted by Get, got length %d", m.Len())
+	}
+	if keys := m.GetKeys(1); len(keys) != 1 || keys[0] != "b" {
+		t.Errorf("Expected keys [b] for value 1, got %v", keys)
+	}
+
+	// Write paths treat the expired key as absent too
+	m.mu.Lock()
+	m.expires["b"] = time.Now().Add(-time.Second)
+	m.mu.Unlock()
+	if !m.SetIfAbsent("b", 2) {
+		t.Errorf("Expected SetIfAbsent to insert over an expired entry")
+	}
+	if m.ContainsValue(1) {
+		t.Errorf("Expected expired value 1 to be dropped from reverse index")
+	}
+}
+
+func TestTTLSetRefreshesDeadline(t *testing.T) {
+	m := NewWithTTL[string, int](time.Hour)
+	defer m.Close()
+
+	m.Set("a", 1)
+	m.mu.Lock()
+	m.expires["a"] = time.Now().Add(-time.Second)
+	m.mu.Unlock()
+
+	// Setting the same value again renews the entry
+	m.Set("a", 1)
+	if val, ok := m.Get("a"); !ok || val != 1 {
+		t.Errorf("Expected a=1 after refresh, got %v, exists: %v", val, ok)
+	}
+}
+
+func TestTTLSweeper(t *testing.T) {
+	m := NewWithTTL[string, int](10 * time.Millisecond)
+	defer m.Close()
+
+	m.Set("a", 1)
+	m.Set("b", 2)
+
+	deadline := time.Now().Add(time.Second)
+	for m.Len() > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if m.Len() != 0 {
+		t.Errorf("Expected sweeper to purge all entries, got length %d", m.Len())
+	}
+	if m.LenValues() != 0 {
+		t.Errorf("Expected sweeper to purge reverse index, got %d values", m.LenValues())
+	}
+}
+
+func TestSetWithTTL(t *testing.T) {
+	m := NewWithTTL[string, int](time.Hour)
+	defer m.Close()
+
+	m.SetWithTTL("token", 1, 10*time.Millisecond)
+	m.SetWithTTL("forever", 2, 0)
+	m.Set("profile", 3)
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := m.Get("token"); ok {
+		t.Errorf("Expected token with short per-key TTL to expire")
+	}
+	if _, ok := m.Get("forever"); !ok {
+		t.Errorf("Expected entry with zero TTL to never expire")
+	}
+	if _, ok := m.Get("profile"); !ok {
+		t.Errorf("Expected entry with default TTL to still be present")
+	}
+
+	m.mu.RLock()
+	_, hasDeadline := m.expires["forever"]
+	m.mu.RUnlock()
+	if hasDeadline {
+		t.Errorf("Expected zero TTL entry to have no deadline")
+	}
+
+	// A plain Set resets a per-key TTL back to the map-wide default
+	m.SetWithTTL("profile", 3, 0)
+	m.Set("profile", 3)
+	m.mu.RLock()
+	_, hasDeadline = m.expires["profile"]
+	m.mu.RUnlock()
+	if !hasDeadline {
+		t.Errorf("Expected Set to restore the default deadline")
+	}
+}
+
+func TestSetWithTTLPlainMap(t *testing.T) {
+	m := New[string, int]()
+
+	m.SetWithTTL("a", 1, 10*time.Millisecond)
+	m.Set("b", 1)
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := m.Get("a"); ok {
+		t.Errorf("Expected per-key TTL to apply on a plain map")
+	}
+	if keys := m.GetKeys(1); len(keys) != 1 || keys[0] != "b" {
+		t.Errorf("Expected keys [b] for value 1, got %v", keys)
+	}
+}
+
+func TestTTLReverseReaders(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 1, "c": 2})
+	expireNow(m, "a", "c")
+
+	// No sweeper runs on a plain map, so only the readers can hide them
+	if keys := m.GetKeys(1); fmt.Sprint(keys) != "[b]" {
+		t.Errorf("Expected keys [b] for value 1, got %v", keys)
+	}
+	if keys := m.GetKeysOr(2, nil); keys != nil {
+		t.Errorf("Expected default for a value held only by expired keys, got %v", keys)
+	}
+	if keys := m.GetKeysMany(1, 2); len(keys) != 1 || fmt.Sprint(keys[1]) != "[b]" {
+		t.Errorf("Expected only value 1 with keys [b], got %v", keys)
+	}
+	if m.ContainsValue(2) || !m.ContainsValue(1) {
+		t.Errorf("Expected ContainsValue to ignore expired keys")
+	}
+	if n := m.CountKeys(1); n != 1 {
+		t.Errorf("Expected 1 live key for value 1, got %d", n)
+	}
+	if counts := m.CountKeysMany(1, 2); counts[1] != 1 || counts[2] != 0 {
+		t.Errorf("Expected counts 1:1 2:0, got %v", counts)
+	}
+	if key, ok := m.GetAny(2); ok {
+		t.Errorf("Expected no key for value 2, got %v", key)
+	}
+	if key, ok := m.GetAny(1); !ok || key != "b" {
+		t.Errorf("Expected b for value 1, got %v, exists: %v", key, ok)
+	}
+	if values := m.DistinctValues(); fmt.Sprint(values) != "[1]" || m.LenValues() != 1 {
+		t.Errorf("Expected distinct values [1], got %v", values)
+	}
+	if counts := m.ValueCounts(); len(counts) != 1 || counts[1] != 1 {
+		t.Errorf("Expected histogram map[1:1], got %v", counts)
+	}
+	if ranked := m.ValuesByFrequency(); len(ranked) != 1 || ranked[0].Value != 1 {
+		t.Errorf("Expected [{1 1}], got %v", ranked)
+	}
+	if keys := m.KeysWhere(func(int) bool { return true }); fmt.Sprint(keys) != "[b]" {
+		t.Errorf("Expected KeysWhere to return [b], got %v", keys)
+	}
+	var visited []string
+	m.RangeKeys(1, func(key string) bool {
+		visited = append(visited, key)
+		return true
+	})
+	m.ForEachValue(func(value int, keys []string) bool {
+		visited = append(visited, fmt.Sprint(value, keys))
+		return true
+	})
+	if fmt.Sprint(visited) != "[b 1 [b]]" {
+		t.Errorf("Expected RangeKeys and ForEachValue to visit only b, got %v", visited)
+	}
+
+	// RemoveValue purges expired keys but only claims live ones
+	if keys := m.RemoveValue(1); fmt.Sprint(keys) != "[b]" {
+		t.Errorf("Expected RemoveValue to claim [b], got %v", keys)
+	}
+	if m.Contains("a") || m.Len() != 1 {
+		t.Errorf("Expected only c to remain, got %v", m.List())
+	}
+}
+
+func TestTTLClose(t *testing.T) {
+	m := NewWithTTL[string, int](time.Millisecond)
+	m.Close()
+	m.Close() // safe to call twice
+
+	// Maps without a sweeper can be closed too
+	New[string, int]().Close()
+
+	// Non-positive TTL disables expiration
+	m = NewWithTTL[string, int](0)
+	m.Set("a", 1)
+	if !m.Contains("a") || m.expires != nil {
+		t.Errorf("Expected zero TTL map to behave like a plain map")
+	}
+}
+
+// expireNow forces the deadlines of the given keys into the past, instead
+// of sleeping for a real TTL.
+func expireNow[K comparable, V comparable](m *Map[K, V], keys ...K) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, k := range keys {
+		m.setDeadlineLocked(k, time.Hour)
+		m.expires[k] = time.Now().Add(-time.Second)
+	}
+}
@@ -0,0 +1,132 @@
+package genericmap
+
+// Tx gives access to a map inside Transaction. Its methods operate directly
+// on the map's internal state and assume the write lock is held, so a Tx is
+// only valid for the duration of the Transaction callback.
+type Tx[K comparable, V comparable] struct {
+	m *Map[K, V]
+}
+
+// Transaction runs fn while holding the map's write lock, so that every
+// operation performed through tx is applied as one atomic unit: no other
+// goroutine can observe the map between them.
+//
+// fn must only use tx and must not call any method on the map itself, which
+// would deadlock. There is no rollback: changes made before fn panics stay
+// applied. Change events recorded by the transaction are delivered after
+// the lock is released.
+//
+// Example:
+//
+//	m.Transaction(func(tx *genericmap.Tx[string, int]) {
+//		if v, ok := tx.Get("a"); ok {
+//			tx.Remove("a")
+//			tx.Set("b", v)
+//		}
+//	})
+func (m *Map[K, V]) Transaction(fn func(tx *Tx[K, V])) {
+	if traceEnabled {
+		defer traceStart("Transaction")()
+	}
+	m.lock()
+	defer m.unlock()
+
+	tx := &Tx[K, V]{m: m}
+	defer func() { tx.m = nil }()
+	fn(tx)
+}
+
+// WithLock runs fn while holding the map's write lock and gives it direct
+// access to the internal forward data and reverse index. It is an escape
+// hatch for compound operations that no method or Transaction covers.
+//
+// fn MUST leave the two maps consistent: for every key k with value v in
+// data, reverse[v] must contain k, and every key in reverse[v] must hold v
+// in data. A value whose last key is removed must be deleted from reverse,
+// not left with an empty set. Breaking this invariant silently corrupts
+// every later reverse lookup.
+//
+// Changes made through fn bypass change hooks, metrics, expiration,
+// insertion order and the sorted key index, so it should not be used on
+// maps relying on those features to add or delete keys. fn must not retain
+// the maps or call any method on the map.
+//
+// On a map created with NewWithMaxKeysPerValue, the per-value key age order
+// is brought back in line with the data after fn returns: keys fn added or
+// moved count as the newest keys of their value. The limit itself is not
+// enforced on fn's changes, so fn must not give a value more keys than it.
+//
+// When built with the genericmap_debug build tag, WithLock runs
+// CheckIntegrity after fn returns and panics if the invariant is broken.
+func (m *Map[K, V]) WithLock(fn func(data map[K]V, reverse map[V]map[K]struct{})) {
+	if traceEnabled {
+		defer traceStart("WithLock")()
+	}
+	m.lock()
+	defer m.unlock()
+
+	fn(m.data, m.reverseMap)
+	m.version.Add(1)
+	if m.fanoutOrder != nil {
+		m.syncFanoutLocked()
+	}
+	if len(m.data) > m.peak {
+		m.peak = len(m.data)
+	}
+	if debugChecks {
+		if err := m.checkIntegrityLocked(); err != nil {
+			panic("genericmap: WithLock callback broke the map invariant: " + err.Error())
+		}
+	}
+}
+
+// Get retrieves the value associated with the key.
+func (tx *Tx[K, V]) Get(key K) (V, bool) {
+	key = tx.m.normKey(key)
+	tx.m.expireLocked(key)
+	val, ok := tx.m.data[key]
+	if ok && tx.m.maxSize > 0 {
+		tx.m.touchLocked(key)
+	}
+	return val, ok
+}
+
+// Contains reports whether the key exists. Unlike Get, it does not count
+// as a use of the key on maps created with NewLRU.
+func (tx *Tx[K, V]) Contains(key K) bool {
+	key = tx.m.normKey(key)
+	_, ok := tx.m.data[key]
+	return ok && !tx.m.expiredLocked(key)
+}
+
+// GetKeys retrieves all keys associated with the value.
+func (tx *Tx[K, V]) GetKeys(value V) []K {
+	tx.m.requireReverse("GetKeys")
+	keyMap := tx.m.reverseMap[value]
+	keys := make([]K, 0, len(keyMap))
+	for key := range keyMap {
+		if !tx.m.expiredLocked(key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Set adds or updates a key-value pair.
+func (tx *Tx[K, V]) Set(key K, value V) {
+	key = tx.m.normKey(key)
+	tx.m.setLocked(key, value)
+}
+
+// Remove removes the key. Returns true if it existed.
+func (tx *Tx[K, V]) Remove(key K) bool {
+	key = tx.m.normKey(key)
+	tx.m.expireLocked(key)
+	_, removed := tx.m.deleteLocked(key)
+	return removed
+}
+
+// Len returns the number of key-value pairs.
+func (tx *Tx[K, V]) Len() int {
+	return len(tx.m.data)
+}
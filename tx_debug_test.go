@@ -0,0 +1,18 @@
+//go:build genericmap_debug
+
+package genericmap
+
+import "testing"
+
+func TestWithLockDebugPanics(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1})
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected WithLock to panic on a broken invariant")
+		}
+	}()
+	m.WithLock(func(data map[string]int, _ map[int]map[string]struct{}) {
+		data["b"] = 2
+	})
+}
@@ -0,0 +1,126 @@
+package genericmap
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestTransaction(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "c": 1})
+
+	m.Transaction(func(tx *Tx[string, int]) {
+		if v, ok := tx.Get("a"); ok {
+			tx.Remove("a")
+			tx.Set("b", v+1)
+		}
+		if !tx.Contains("b") || tx.Contains("a") {
+			t.Errorf("Expected transaction to see its own writes")
+		}
+		if keys := tx.GetKeys(1); len(keys) != 1 || keys[0] != "c" {
+			t.Errorf("Expected keys [c] for value 1 inside transaction, got %v", keys)
+		}
+		if tx.Len() != 2 {
+			t.Errorf("Expected length 2 inside transaction, got %d", tx.Len())
+		}
+	})
+
+	if m.Contains("a") {
+		t.Errorf("Expected a to be removed")
+	}
+	if val, _ := m.Get("b"); val != 2 {
+		t.Errorf("Expected b=2, got %v", val)
+	}
+}
+
+func TestTransactionContainsKeepsRecency(t *testing.T) {
+	m := NewLRU[string, int](2)
+	m.Set("a", 1)
+	m.Set("b", 2)
+
+	m.Transaction(func(tx *Tx[string, int]) {
+		if !tx.Contains("a") {
+			t.Errorf("Expected a to exist")
+		}
+	})
+
+	// a is still the least recently used key and is evicted first
+	m.Set("c", 3)
+	if m.Contains("a") || !m.Contains("b") {
+		t.Errorf("Expected Contains not to refresh a, got %v", m.List())
+	}
+}
+
+func TestTransactionAtomic(t *testing.T) {
+	// Move a token between two keys; readers must never see zero or two tokens
+	m := New[string, bool](map[string]bool{"left": true})
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 500; i++ {
+			m.Transaction(func(tx *Tx[string, bool]) {
+				if tx.Remove("left") {
+					tx.Set("right", true)
+				} else {
+					tx.Remove("right")
+					tx.Set("left", true)
+				}
+			})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 500; i++ {
+			if n := m.Len(); n != 1 {
+				t.Errorf("Observed %d tokens mid-transaction", n)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+}
+
+func TestTransactionEventsAfterUnlock(t *testing.T) {
+	m := New[string, int]()
+
+	var events int
+	m.OnChange(func(ChangeEvent[string, int]) {
+		// Reading the map here would deadlock if the lock were still held
+		_ = m.Len()
+		events++
+	})
+
+	m.Transaction(func(tx *Tx[string, int]) {
+		tx.Set("a", 1)
+		tx.Set("b", 2)
+		tx.Remove("a")
+	})
+	if events != 3 {
+		t.Errorf("Expected 3 events, got %d", events)
+	}
+}
+
+func TestWithLock(t *testing.T) {
+	m := New[string, int](map[string]int{"a": 1, "b": 1, "c": 2})
+
+	// Move every key holding 1 to 3 in one pass over the reverse index
+	m.WithLock(func(data map[string]int, reverse map[int]map[string]struct{}) {
+		keys := reverse[1]
+		delete(reverse, 1)
+		for k := range keys {
+			data[k] = 3
+		}
+		reverse[3] = keys
+	})
+
+	if m.ContainsValue(1) {
+		t.Errorf("Expected value 1 to be gone")
+	}
+	if n := m.CountKeys(3); n != 2 {
+		t.Errorf("Expected 2 keys for value 3, got %d", n)
+	}
+	if err := m.CheckIntegrity(); err != nil {
+		t.Errorf("Expected consistent map, got %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package genericmap
+
+import (
+	"runtime"
+	"sync"
+	"weak"
+)
+
+// WeakMap is a thread-safe, generic bidirectional map that holds its values
+// through weak pointers, so that values referenced nowhere else can be
+// reclaimed by the garbage collector. It suits memory-sensitive caches that
+// should shrink under GC pressure instead of being bounded conservatively.
+//
+// Values are identified by pointer: the reverse index groups keys by the
+// object they point to, not by the contents of that object. Once a value
+// has been collected its entries disappear: lookups treat them as absent,
+// and they are removed from both indexes lazily by Get and in the
+// background after collection.
+type WeakMap[K comparable, V any] struct {
+	data       map[K]weak.Pointer[V]
+	reverseMap map[weak.Pointer[V]]map[K]struct{}
+	cleanups   map[weak.Pointer[V]]runtime.Cleanup // one per tracked live value
+	mu         sync.RWMutex
+}
+
+// NewWeakValues creates a new WeakMap.
+func NewWeakValues[K comparable, V any]() *WeakMap[K, V] {
+	return &WeakMap[K, V]{
+		data:       make(map[K]weak.Pointer[V]),
+		reverseMap: make(map[weak.Pointer[V]]map[K]struct{}),
+		cleanups:   make(map[weak.Pointer[V]]runtime.Cleanup),
+	}
+}
+
+// Set associates the key with value without keeping value alive.
+// A nil value is stored as an entry that is already collected.
+func (m *WeakMap[K, V]) Set(key K, value *V) {
+	wp := weak.Make(value)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if old, exists := m.data[key]; exists {
+		if old == wp {
+			return
+		}
+		m.removeFromReverseMap(key, old)
+	}
+	m.data[key] = wp
+	keyMap, tracked := m.reverseMap[wp]
+	if !tracked {
+		keyMap = make(map[K]struct{})
+		m.reverseMap[wp] = keyMap
+		if value != nil {
+			// Drop every entry for the value once it has been collected.
+			// The cleanup is stopped again if the value stops being tracked
+			// while still alive, so repeated Set and Remove do not pile up
+			// cleanups on it.
+			m.cleanups[wp] = runtime.AddCleanup(value, m.purge, wp)
+		}
+	}
+	keyMap[key] = struct{}{}
+}
+
+// Get returns the value associated with the key. It returns nil and false
+// if the key is absent or its value has been collected, in which case the
+// dead entry is removed.
+func (m *WeakMap[K, V]) Get(key K) (*V, bool) {
+	m.mu.RLock()
+	wp, ok := m.data[key]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	if value := wp.Value(); value != nil {
+		return value, true
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// The key may have been set again since it was read
+	if current, ok := m.data[key]; ok && current == wp {
+		delete(m.data, key)
+		m.removeFromReverseMap(key, wp)
+	}
+	return nil, false
+}
+
+// GetKeys retrieves all keys associated with the given value pointer.
+// It returns an empty slice for a nil value.
+func (m *WeakMap[K, V]) GetKeys(value *V) []K {
+	if value == nil {
+		return []K{}
+	}
+	wp := weak.Make(value)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keyMap := m.reverseMap[wp]
+	result := make([]K, 0, len(keyMap))
+	for key := range keyMap {
+		result = append(result, key)
+	}
+	return result
+}
+
+// Remove deletes the key from the map.
+// Returns true if the key was present, even if its value had been collected.
+func (m *WeakMap[K, V]) Remove(key K) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	wp, exists := m.data[key]
+	if !exists {
+		return false
+	}
+	delete(m.data, key)
+	m.removeFromReverseMap(key, wp)
+	return true
+}
+
+// Len returns the number of entries in the map. Entries whose values have
+// been collected but not yet removed are still counted.
+func (m *WeakMap[K, V]) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.data)
+}
+
+// purge removes every entry pointing to a collected value. It runs as a
+// runtime cleanup once the value is unreachable.
+func (m *WeakMap[K, V]) purge(wp weak.Pointer[V]) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key := range m.reverseMap[wp] {
+		delete(m.data, key)
+	}
+	delete(m.reverseMap, wp)
+	delete(m.cleanups, wp)
+}
+
+// removeFromReverseMap removes a key from the reverse index for a value.
+// This is an internal method and assumes the caller holds the write lock.
+func (m *WeakMap[K, V]) removeFromReverseMap(key K, wp weak.Pointer[V]) {
+	if keyMap, exists := m.reverseMap[wp]; exists {
+		delete(keyMap, key)
+		if len(keyMap) == 0 {
+			delete(m.reverseMap, wp)
+			if cleanup, ok := m.cleanups[wp]; ok {
+				cleanup.Stop()
+				delete(m.cleanups, wp)
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package genericmap
+
+import (
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+)
+
+type blob struct {
+	data [1024]byte
+	name string
+}
+
+func TestWeakMap(t *testing.T) {
+	m := NewWeakValues[string, blob]()
+
+	shared := &blob{name: "shared"}
+	other := &blob{name: "other"}
+	m.Set("a", shared)
+	m.Set("b", shared)
+	m.Set("c", other)
+
+	if v, ok := m.Get("a"); !ok || v != shared {
+		t.Errorf("Expected a to hold the shared blob, got %v, exists: %v", v, ok)
+	}
+	keys := m.GetKeys(shared)
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+		t.Errorf("Expected keys [a b] for shared blob, got %v", keys)
+	}
+
+	m.Set("b", other)
+	if keys := m.GetKeys(other); len(keys) != 2 {
+		t.Errorf("Expected 2 keys for other blob after update, got %v", keys)
+	}
+	if !m.Remove("a") || m.Remove("a") {
+		t.Errorf("Expected Remove to report presence once")
+	}
+	if m.Len() != 2 {
+		t.Errorf("Expected length 2, got %d", m.Len())
+	}
+	if keys := m.GetKeys(shared); len(keys) != 0 {
+		t.Errorf("Expected no keys for shared blob, got %v", keys)
+	}
+	runtime.KeepAlive(shared)
+	runtime.KeepAlive(other)
+}
+
+func TestWeakMapCollected(t *testing.T) {
+	m := NewWeakValues[int, blob]()
+	func() {
+		v := &blob{name: "temporary"}
+		m.Set(1, v)
+		m.Set(2, v)
+	}()
+	kept := &blob{name: "kept"}
+	m.Set(3, kept)
+
+	// Collected values read as absent, and their entries are purged
+	deadline := time.Now().Add(5 * time.Second)
+	for m.Len() != 1 && time.Now().Before(deadline) {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+	if _, ok := m.Get(1); ok {
+		t.Errorf("Expected collected value to be absent")
+	}
+	if m.Len() != 1 {
+		t.Errorf("Expected collected entries to be purged, got length %d", m.Len())
+	}
+	if v, ok := m.Get(3); !ok || v.name != "kept" {
+		t.Errorf("Expected live value to survive collection, got %v, exists: %v", v, ok)
+	}
+	runtime.KeepAlive(kept)
+}
+
+func TestWeakMapCleanupsBounded(t *testing.T) {
+	m := NewWeakValues[int, blob]()
+	v := &blob{name: "live"}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	for i := 0; i < 200000; i++ {
+		m.Set(1, v)
+		m.Remove(1)
+	}
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+
+	if n := len(m.cleanups); n != 0 {
+		t.Errorf("Expected no cleanups for an untracked value, got %d", n)
+	}
+	if growth := int64(after.HeapAlloc) - int64(before.HeapAlloc); growth > 1<<20 {
+		t.Errorf("Expected heap to stay bounded across Set and Remove, grew by %d bytes", growth)
+	}
+
+	// A value that is tracked again gets a single cleanup
+	m.Set(1, v)
+	m.Set(2, v)
+	if len(m.cleanups) != 1 {
+		t.Errorf("Expected one cleanup for a tracked value, got %d", len(m.cleanups))
+	}
+	runtime.KeepAlive(v)
+}